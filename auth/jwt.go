@@ -80,6 +80,15 @@ func GenerateCSRFToken() string {
 	return hex.EncodeToString(b)
 }
 
+// GenerateCalendarToken 生成日历订阅链接中的密钥
+func GenerateCalendarToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // GenerateEmailCode 生成6位邮箱验证码
 func GenerateEmailCode() (string, error) {
 	b := make([]byte, 3)
@@ -58,12 +58,6 @@ func ValidateRole(role string) bool {
 	return role == "interviewee" || role == "interviewer"
 }
 
-// ValidateStatus 验证面试状态是否合法
-func ValidateStatus(status string) bool {
-	validStatuses := []string{"r1_pending", "r1_passed", "r2_pending", "r2_passed", "rejected", "offer"}
-	return slices.Contains(validStatuses, status)
-}
-
 // ValidateEmailCodePurpose 验证邮箱验证码用途
 func ValidateEmailCodePurpose(purpose string) bool {
 	validPurposes := []string{"register", "reset", "profile"}
@@ -88,3 +82,11 @@ func ValidateDirections(directions []string) bool {
 	}
 	return true
 }
+
+// colorPattern 标签颜色，#RRGGBB 格式
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidateColor 验证颜色是否为 #RRGGBB 格式
+func ValidateColor(color string) bool {
+	return colorPattern.MatchString(color)
+}
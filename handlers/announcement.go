@@ -29,7 +29,7 @@ func CreateAnnouncement(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
 			return
 		}
-		if !validateAnnouncementVisibility(req.Visibility, req.AllowedStatuses) {
+		if !validateAnnouncementVisibility(db, req.Visibility, req.AllowedStatuses) {
 			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "可见范围参数校验失败"})
 			return
 		}
@@ -46,6 +46,14 @@ func CreateAnnouncement(db *gorm.DB) gin.HandlerFunc {
 			allowedStatuses = []string{}
 		}
 		allowedStatusesJSON, _ := json.Marshal(allowedStatuses)
+
+		// 公告归属当前招新季
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
 		// 创建公告
 		announcementUUID, _ := uuid.NewUUID()
 		announcement := models.Announcement{
@@ -56,6 +64,7 @@ func CreateAnnouncement(db *gorm.DB) gin.HandlerFunc {
 			AuthorId:        userUUID,
 			Visibility:      req.Visibility,
 			AllowedStatuses: string(allowedStatusesJSON),
+			SeasonID:        seasonID,
 		}
 
 		if err := db.Create(&announcement).Error; err != nil {
@@ -89,7 +98,7 @@ func UpdateAnnouncement(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
 			return
 		}
-		if !validateAnnouncementVisibility(req.Visibility, req.AllowedStatuses) {
+		if !validateAnnouncementVisibility(db, req.Visibility, req.AllowedStatuses) {
 			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "可见范围参数校验失败"})
 			return
 		}
@@ -170,8 +179,15 @@ func PinAnnouncement(db *gorm.DB) gin.HandlerFunc {
 // GetAnnouncements 获取公告列表
 func GetAnnouncements(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 指定招新季，默认为当前招新季
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
 		var announcements []models.Announcement
-		query := db.Model(&models.Announcement{})
+		query := db.Model(&models.Announcement{}).Where("season_id = ?", season.UUID)
 
 		// 尝试解析登录信息（可选）
 		sessionID, _ := c.Cookie("session_id")
@@ -269,7 +285,7 @@ func parseJSONList(raw string) []string {
 	return values
 }
 
-func validateAnnouncementVisibility(visibility string, allowedStatuses []string) bool {
+func validateAnnouncementVisibility(db *gorm.DB, visibility string, allowedStatuses []string) bool {
 	valid := map[string]bool{
 		"public":      true,
 		"all":         true,
@@ -284,7 +300,7 @@ func validateAnnouncementVisibility(visibility string, allowedStatuses []string)
 			return false
 		}
 		for _, item := range allowedStatuses {
-			if !auth.ValidateStatus(item) {
+			if !validateStatus(db, item) {
 				return false
 			}
 		}
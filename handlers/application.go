@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"maps"
 	"net/http"
+	"slices"
+	"time"
 	"xdsec-join-2026/auth"
 	"xdsec-join-2026/models"
+	"xdsec-join-2026/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,13 +18,15 @@ import (
 // CreateApplicationRequest 创建申请请求
 type CreateApplicationRequest struct {
 	RealName   string   `json:"realName" binding:"required,max=10"`
-	Phone      string   `json:"phone" binding:"required,max=15"`
+	Phone      string   `json:"phone" binding:"required,max=32"`
 	Gender     string   `json:"gender" binding:"required"`
 	Department string   `json:"department" binding:"required,max=20"`
 	Major      string   `json:"major" binding:"required,max=20"`
 	StudentId  string   `json:"studentId" binding:"required,max=20"`
 	Directions []string `json:"directions" binding:"required,max=30"`
 	Resume     string   `json:"resume" binding:"required,max=10000"`
+	// Answers 申请表自定义问题的回答
+	Answers []ApplicationAnswerItem `json:"answers" binding:"max=100,dive"`
 }
 
 // CreateApplication 创建申请
@@ -28,27 +34,11 @@ func CreateApplication(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateApplicationRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
-			return
-		}
-
-		// 兼容中文输入
-		switch req.Gender {
-		case "男":
-			req.Gender = "male"
-		case "女":
-			req.Gender = "female"
-		}
-
-		// 验证性别
-		if req.Gender != "male" && req.Gender != "female" {
-			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "后端不承认非二元性别"})
-			return
-		}
-
-		// 验证方向
-		if !auth.ValidateDirections(req.Directions) {
-			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			body := gin.H{"ok": false, "message": "参数校验失败"}
+			if errs := bindingErrors(err, &req); errs != nil {
+				body["errors"] = errs
+			}
+			c.JSON(http.StatusBadRequest, body)
 			return
 		}
 
@@ -59,81 +49,204 @@ func CreateApplication(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 序列化方向
-		directionsJSON, _ := json.Marshal(req.Directions)
+		code, body := saveApplication(db, userUUID, req)
+		c.JSON(code, body)
+	}
+}
 
-		// 检查是否已存在申请
-		var existingApp models.Application
-		if err := db.Where("user_id = ?", userUUID).First(&existingApp).Error; err == nil {
-			// 申请已存在，检查是否有数据变化
-			directionsMatch := existingApp.Directions == string(directionsJSON)
-			dataChanged := existingApp.RealName != req.RealName ||
-				existingApp.Phone != req.Phone ||
-				existingApp.Gender != req.Gender ||
-				existingApp.Department != req.Department ||
-				existingApp.Major != req.Major ||
-				existingApp.StudentId != req.StudentId ||
-				!directionsMatch ||
-				existingApp.Resume != req.Resume
+// saveApplication 校验并保存当前招新季的申请，返回HTTP状态码与响应内容
+// 直接提交与草稿提交共用该逻辑
+func saveApplication(db *gorm.DB, userUUID uuid.UUID, req CreateApplicationRequest) (int, gin.H) {
+	// 兼容中文输入
+	switch req.Gender {
+	case "男":
+		req.Gender = "male"
+	case "女":
+		req.Gender = "female"
+	}
 
-			if !dataChanged {
-				c.JSON(http.StatusTeapot, gin.H{"ok": false, "message": "申请数据未发生变化"})
-				return
-			}
+	// 验证性别
+	if req.Gender != "male" && req.Gender != "female" {
+		return http.StatusBadRequest, gin.H{"ok": false, "message": "后端不承认非二元性别", "errors": gin.H{"gender": "后端不承认非二元性别"}}
+	}
 
-			// 数据有变化，更新申请
-			updates := map[string]interface{}{
-				"real_name":  req.RealName,
-				"phone":      req.Phone,
-				"gender":     req.Gender,
-				"department": req.Department,
-				"major":      req.Major,
-				"student_id": req.StudentId,
-				"directions": string(directionsJSON),
-				"resume":     req.Resume,
-			}
+	// 验证方向
+	if !auth.ValidateDirections(req.Directions) {
+		return http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败", "errors": gin.H{"directions": "方向不合法"}}
+	}
 
-			if err := db.Model(&existingApp).Updates(updates).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器更新申请时发生错误"})
-				return
-			}
+	// 申请归属当前招新季，往届成员可以在新招新季重新申请
+	season, err := currentSeason(db)
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"}
+	}
 
-			// 更新用户的方向信息
-			if err := db.Model(&models.User{}).Where("uuid = ?", userUUID).Update("directions", string(directionsJSON)).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器更新用户方向时发生错误"})
-				return
+	// 校验各字段的格式与自定义问题的回答，错误按字段返回
+	fieldErrs := validateApplicationFields(db, &req)
+	questions, err := loadFormQuestions(db, season.UUID)
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"}
+	}
+	answers, answerErrs := validateAnswers(questions, req.Directions, req.Answers)
+	for questionID, message := range answerErrs {
+		fieldErrs["answers."+questionID] = message
+	}
+	if len(fieldErrs) > 0 {
+		return http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败", "errors": fieldErrs}
+	}
+	studentYear, _ := enrollmentYear(req.StudentId, time.Now())
+
+	// 序列化方向
+	directionsJSON, _ := json.Marshal(req.Directions)
+
+	// 检查本招新季是否已存在申请
+	var existingApp models.Application
+	if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).First(&existingApp).Error; err == nil {
+		// 截止后不能修改申请，原有方向与新方向都需要在开放时间内
+		windowDirections := parseJSONList(existingApp.Directions)
+		for _, direction := range req.Directions {
+			if !slices.Contains(windowDirections, direction) {
+				windowDirections = append(windowDirections, direction)
 			}
+		}
+		windowErr, err := checkApplicationWindow(db, season, userUUID, windowDirections, time.Now())
+		if err != nil {
+			return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"}
+		}
+		if windowErr != nil {
+			return http.StatusForbidden, windowErrorBody(windowErr)
+		}
 
-			c.JSON(http.StatusOK, gin.H{"ok": true, "message": "修改申请信息成功"})
-			return
+		// 申请已存在，检查是否有数据变化
+		directionsMatch := existingApp.Directions == string(directionsJSON)
+		dataChanged := existingApp.RealName != req.RealName ||
+			existingApp.Phone != req.Phone ||
+			existingApp.Gender != req.Gender ||
+			existingApp.Department != req.Department ||
+			existingApp.Major != req.Major ||
+			existingApp.StudentId != req.StudentId ||
+			!directionsMatch ||
+			existingApp.Resume != req.Resume ||
+			!maps.Equal(loadApplicationAnswers(db, existingApp.ID), answers)
+
+		if !dataChanged {
+			return http.StatusTeapot, gin.H{"ok": false, "message": "申请数据未发生变化"}
+		}
+
+		// 数据有变化，更新申请
+		updates := map[string]interface{}{
+			"real_name":       req.RealName,
+			"phone":           req.Phone,
+			"gender":          req.Gender,
+			"department":      req.Department,
+			"major":           req.Major,
+			"student_id":      req.StudentId,
+			"enrollment_year": studentYear,
+			"directions":      string(directionsJSON),
+			"resume":          req.Resume,
+		}
+		for column, value := range duplicateKeyColumns(req.RealName, req.Phone, req.StudentId) {
+			updates[column] = value
 		}
 
-		// 创建申请
-		application := models.Application{
+		// 每次修改都记录为新的修订版本
+		snapshot := applicationSnapshot{
 			RealName:   req.RealName,
 			Phone:      req.Phone,
 			Gender:     req.Gender,
 			Department: req.Department,
 			Major:      req.Major,
 			StudentId:  req.StudentId,
-			Directions: string(directionsJSON),
+			Directions: req.Directions,
 			Resume:     req.Resume,
-			UserID:     userUUID,
+			Answers:    answerSnapshot(answers),
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := ensureBaselineRevision(tx, existingApp); err != nil {
+				return err
+			}
+			if err := tx.Model(&existingApp).Updates(updates).Error; err != nil {
+				return err
+			}
+			if err := saveApplicationAnswers(tx, existingApp.ID, answers); err != nil {
+				return err
+			}
+			return createApplicationRevision(tx, existingApp, snapshot, userUUID, time.Now())
+		})
+		if err != nil {
+			return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器更新申请时发生错误"}
 		}
 
-		if err := db.Create(&application).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器创建申请时发生错误"})
-			return
+		if code, body := syncApplicationDirections(db, userUUID, string(directionsJSON)); code != http.StatusOK {
+			return code, body
 		}
 
-		// 更新用户的方向信息
-		if err := db.Model(&models.User{}).Where("uuid = ?", userUUID).Update("directions", string(directionsJSON)).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器更新用户方向时发生错误"})
-			return
+		return http.StatusOK, gin.H{"ok": true, "message": "修改申请信息成功"}
+	}
+
+	// 检查申请开放时间
+	windowErr, err := checkApplicationWindow(db, season, userUUID, req.Directions, time.Now())
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"}
+	}
+	if windowErr != nil {
+		return http.StatusForbidden, windowErrorBody(windowErr)
+	}
+
+	// 创建申请
+	application := models.Application{
+		RealName:       req.RealName,
+		Phone:          req.Phone,
+		Gender:         req.Gender,
+		Department:     req.Department,
+		Major:          req.Major,
+		StudentId:      req.StudentId,
+		EnrollmentYear: studentYear,
+		Directions:     string(directionsJSON),
+		Resume:         req.Resume,
+		UserID:         userUUID,
+		SeasonID:       season.UUID,
+	}
+	keys := duplicateKeyColumns(req.RealName, req.Phone, req.StudentId)
+	application.PhoneKey = keys["phone_key"].(string)
+	application.StudentIdKey = keys["student_id_key"].(string)
+	application.NameKey = keys["name_key"].(string)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&application).Error; err != nil {
+			return err
 		}
+		if err := saveApplicationAnswers(tx, application.ID, answers); err != nil {
+			return err
+		}
+		snapshot := applicationSnapshotOf(application)
+		snapshot.Answers = answerSnapshot(answers)
+		return createApplicationRevision(tx, application, snapshot, userUUID, application.CreatedAt)
+	})
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器创建申请时发生错误"}
+	}
 
-		c.JSON(http.StatusOK, gin.H{"ok": true})
+	if code, body := syncApplicationDirections(db, userUUID, string(directionsJSON)); code != http.StatusOK {
+		return code, body
+	}
+
+	return http.StatusOK, gin.H{"ok": true}
+}
+
+// syncApplicationDirections 申请保存后更新用户的方向信息并同步各方向的面试状态
+func syncApplicationDirections(db *gorm.DB, userUUID uuid.UUID, directionsJSON string) (int, gin.H) {
+	// 更新用户的方向信息
+	if err := db.Model(&models.User{}).Where("uuid = ?", userUUID).Update("directions", directionsJSON).Error; err != nil {
+		return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器更新用户方向时发生错误"}
+	}
+
+	// 同步各方向的面试状态
+	if err := syncDirectionStatuses(db, userUUID); err != nil {
+		return http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器同步方向状态时发生错误"}
 	}
+
+	return http.StatusOK, nil
 }
 
 // GetMyApplication 获取我的申请
@@ -146,23 +259,31 @@ func GetMyApplication(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// 指定招新季，默认为当前招新季
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
 		// 查找申请
 		var application models.Application
-		if err := db.Where("user_id = ?", userUUID).First(&application).Error; err != nil {
+		if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).First(&application).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "你还没有提交申请吧"})
 			return
 		}
 
 		appData := gin.H{
-			"realName":   application.RealName,
-			"phone":      application.Phone,
-			"gender":     application.Gender,
-			"department": application.Department,
-			"major":      application.Major,
-			"studentId":  application.StudentId,
-			"resume":     application.Resume,
-			"createdAt":  application.CreatedAt,
-			"updatedAt":  application.UpdatedAt,
+			"realName":       application.RealName,
+			"phone":          application.Phone,
+			"gender":         application.Gender,
+			"department":     application.Department,
+			"major":          application.Major,
+			"studentId":      application.StudentId,
+			"enrollmentYear": application.EnrollmentYear,
+			"resume":         application.Resume,
+			"createdAt":      application.CreatedAt,
+			"updatedAt":      application.UpdatedAt,
 		}
 
 		if application.Directions != "" {
@@ -171,6 +292,19 @@ func GetMyApplication(db *gorm.DB) gin.HandlerFunc {
 			appData["directions"] = directions
 		}
 
+		// 自定义问题的回答
+		if questions, err := loadFormQuestions(db, season.UUID); err == nil {
+			appData["answers"] = answerData(questions, loadApplicationAnswers(db, application.ID))
+		}
+		appData["attachments"] = attachmentList(loadApplicationAttachments(db, application.ID))
+
+		// 各方向的面试状态
+		var directionStatuses []models.DirectionStatus
+		db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).Find(&directionStatuses)
+		appData["directionStatuses"] = directionStatusData(directionStatuses)
+		appData["season"] = seasonData(*season)
+		appData["seasons"] = loadApplicationSeasons(db, userUUID)
+
 		c.JSON(http.StatusOK, gin.H{
 			"ok":   true,
 			"data": appData,
@@ -194,23 +328,31 @@ func GetApplicationDetail(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// 指定招新季，默认为当前招新季
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
 		// 查找申请
 		var application models.Application
-		if err := db.Where("user_id = ?", userUUID).First(&application).Error; err != nil {
+		if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).First(&application).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "申请不存在"})
 			return
 		}
 
 		appData := gin.H{
-			"realName":   application.RealName,
-			"phone":      application.Phone,
-			"gender":     application.Gender,
-			"department": application.Department,
-			"major":      application.Major,
-			"studentId":  application.StudentId,
-			"resume":     application.Resume,
-			"createdAt":  application.CreatedAt,
-			"updatedAt":  application.UpdatedAt,
+			"realName":       application.RealName,
+			"phone":          application.Phone,
+			"gender":         application.Gender,
+			"department":     application.Department,
+			"major":          application.Major,
+			"studentId":      application.StudentId,
+			"enrollmentYear": application.EnrollmentYear,
+			"resume":         application.Resume,
+			"createdAt":      application.CreatedAt,
+			"updatedAt":      application.UpdatedAt,
 		}
 
 		if application.Directions != "" {
@@ -219,6 +361,30 @@ func GetApplicationDetail(db *gorm.DB) gin.HandlerFunc {
 			appData["directions"] = directions
 		}
 
+		// 自定义问题的回答
+		if questions, err := loadFormQuestions(db, season.UUID); err == nil {
+			appData["answers"] = answerData(questions, loadApplicationAnswers(db, application.ID))
+		}
+		appData["attachments"] = attachmentList(loadApplicationAttachments(db, application.ID))
+
+		// 往届申请记录
+		appData["season"] = seasonData(*season)
+		appData["seasons"] = loadApplicationSeasons(db, userUUID)
+
+		// 状态变更后又修改了申请时给出提示
+		appData["warnings"] = applicationWarnings(db, application)
+
+		// 匿名评审轮次中的面试者对面试官隐藏身份，只作用于当前招新季
+		appData["blind"] = false
+		if currentID, err := currentSeasonID(db); err == nil && currentID == season.UUID {
+			var user models.User
+			if err := db.Where("uuid = ?", userUUID).First(&user).Error; err == nil && loadBlindView(db, c, season.UUID).masked(user) {
+				maskApplicationData(appData)
+				appData["blind"] = true
+				appData["pseudonym"] = pseudonym(season.UUID, user.UUID)
+			}
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"ok":   true,
 			"data": appData,
@@ -228,7 +394,10 @@ func GetApplicationDetail(db *gorm.DB) gin.HandlerFunc {
 
 // SetInterviewStatusRequest 设置面试状态请求
 type SetInterviewStatusRequest struct {
-	Status string `json:"status" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Direction string `json:"direction"`
+	Reason    string `json:"reason" binding:"max=200"`
+	Force     bool   `json:"force"`
 }
 
 // SetInterviewStatus 设置面试状态（面试官）
@@ -246,9 +415,9 @@ func SetInterviewStatus(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 验证状态
-		if !auth.ValidateStatus(req.Status) {
-			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "要设置的面试状态不合法"})
+		// 验证方向（不指定时修改所有可流转的方向）
+		if req.Direction != "" && !auth.ValidateDirection(req.Direction) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "方向参数校验失败"})
 			return
 		}
 
@@ -266,9 +435,24 @@ func SetInterviewStatus(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 更新状态
-		if err := db.Model(&user).Update("status", req.Status).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+		// 获取当前用户（面试官）
+		currentUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		// 跳过流转校验需要管理员权限
+		if req.Force && !IsAdmin(db, currentUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "强制修改状态需要管理员权限"})
+			return
+		}
+
+		// 校验并更新状态
+		change := statusChange{Status: req.Status, Direction: req.Direction, Reason: req.Reason, ActorID: currentUUID, Force: req.Force}
+		if err := changeInterviewStatus(db, &user, change); err != nil {
+			code, message := statusErrorResponse(err)
+			c.JSON(code, gin.H{"ok": false, "message": message})
 			return
 		}
 
@@ -276,8 +460,122 @@ func SetInterviewStatus(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// BulkSetInterviewStatusRequest 批量设置面试状态请求
+type BulkSetInterviewStatusRequest struct {
+	ViewID    string   `json:"viewId"`
+	UserIDs   []string `json:"userIds"`
+	Status    string   `json:"status" binding:"required"`
+	Direction string   `json:"direction"`
+	Reason    string   `json:"reason" binding:"max=200"`
+	Force     bool     `json:"force"`
+}
+
+// BulkSetInterviewStatus 批量设置面试状态（面试官），对象为保存视图中的面试者或指定的用户列表
+func BulkSetInterviewStatus(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BulkSetInterviewStatusRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "检查一下 request body 吧"})
+			return
+		}
+
+		if (req.ViewID == "") == (len(req.UserIDs) == 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "viewId 与 userIds 需要且只能提供一个"})
+			return
+		}
+
+		// 验证状态
+		if !validateStatus(db, req.Status) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "要设置的面试状态不合法"})
+			return
+		}
+
+		// 验证方向
+		if req.Direction != "" && !auth.ValidateDirection(req.Direction) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "方向参数校验失败"})
+			return
+		}
+
+		currentUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		// 跳过流转校验需要管理员权限
+		if req.Force && !IsAdmin(db, currentUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "强制修改状态需要管理员权限"})
+			return
+		}
+
+		// 确定批量操作的对象（仅限面试者）
+		tx := db.Model(&models.User{}).Where("role = ?", "interviewee")
+		if req.ViewID != "" {
+			viewUUID, err := uuid.Parse(req.ViewID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "viewId 参数校验失败"})
+				return
+			}
+			view, err := loadAccessibleView(db, viewUUID, currentUUID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "视图不存在"})
+				return
+			}
+			filter, err := savedViewFilter(*view)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "视图过滤条件已损坏"})
+				return
+			}
+			seasonID, err := currentSeasonID(db)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			tx = filter.Apply(db, tx, true, loadBlindView(db, c, seasonID))
+		} else {
+			userUUIDs := make([]uuid.UUID, 0, len(req.UserIDs))
+			for _, id := range req.UserIDs {
+				userUUID, err := uuid.Parse(id)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "userIds 参数校验失败"})
+					return
+				}
+				userUUIDs = append(userUUIDs, userUUID)
+			}
+			tx = tx.Where("uuid IN ?", userUUIDs)
+		}
+
+		var users []models.User
+		if err := tx.Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		// 逐个校验并更新，失败的记录原因后继续处理其他人
+		updated := 0
+		failures := make([]gin.H, 0)
+		change := statusChange{Status: req.Status, Direction: req.Direction, Reason: req.Reason, ActorID: currentUUID, Force: req.Force}
+		for i := range users {
+			if err := changeInterviewStatus(db, &users[i], change); err != nil {
+				_, message := statusErrorResponse(err)
+				failures = append(failures, gin.H{"userId": users[i].UUID.String(), "message": message})
+				continue
+			}
+			updated++
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"updated":  updated,
+				"failures": failures,
+			},
+		})
+	}
+}
+
 // DeleteApplication 删除申请（面试官）
-func DeleteApplication(db *gorm.DB) gin.HandlerFunc {
+func DeleteApplication(db *gorm.DB, store storage.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.Param("userId")
 		if userID == "" {
@@ -292,25 +590,65 @@ func DeleteApplication(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 查找申请
+		// 查找当前招新季的申请，往届申请作为历史保留
+		season, err := currentSeason(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
 		var application models.Application
-		if err := db.Where("user_id = ?", userUUID).First(&application).Error; err != nil {
+		if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).First(&application).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "申请不存在"})
 			return
 		}
 
-		// 删除申请
-		if err := db.Delete(&application).Error; err != nil {
+		// 与修改申请相同，截止后不能撤回申请
+		windowErr, err := checkApplicationWindow(db, season, userUUID, parseJSONList(application.Directions), time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if windowErr != nil {
+			c.JSON(http.StatusForbidden, windowErrorBody(windowErr))
+			return
+		}
+
+		// 删除申请及其问题回答、附件与面试官分配，释放面试预约
+		var attachments []models.Attachment
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("application_id = ?", application.ID).Delete(&models.ApplicationAnswer{}).Error; err != nil {
+				return err
+			}
+			if attachments, err = deleteAttachmentRecords(tx, "application_id = ?", application.ID); err != nil {
+				return err
+			}
+			if err := releaseUserBookings(tx, "user_id = ? AND season_id = ?", application.UserID, application.SeasonID); err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ? AND season_id = ?", application.UserID, application.SeasonID).Delete(&models.Assignment{}).Error; err != nil {
+				return err
+			}
+			if err := deleteScorecards(tx, "user_id = ? AND season_id = ?", application.UserID, application.SeasonID); err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ? AND season_id = ?", application.UserID, application.SeasonID).Delete(&models.BlindReveal{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&application).Error
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
+		purgeAttachmentFiles(store, attachments)
 
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	}
 }
 
 // DeleteSelfApplication 删除自己的申请
-func DeleteSelfApplication(db *gorm.DB) gin.HandlerFunc {
+func DeleteSelfApplication(db *gorm.DB, store storage.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取当前用户
 		userUUID, ok := GetCurrentUserUUID(c)
@@ -319,18 +657,58 @@ func DeleteSelfApplication(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 查找申请
+		// 查找当前招新季的申请，往届申请作为历史保留
+		season, err := currentSeason(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
 		var application models.Application
-		if err := db.Where("user_id = ?", userUUID).First(&application).Error; err != nil {
+		if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).First(&application).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "申请不存在"})
 			return
 		}
 
-		// 删除申请
-		if err := db.Delete(&application).Error; err != nil {
+		// 与修改申请相同，截止后不能撤回申请
+		windowErr, err := checkApplicationWindow(db, season, userUUID, parseJSONList(application.Directions), time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if windowErr != nil {
+			c.JSON(http.StatusForbidden, windowErrorBody(windowErr))
+			return
+		}
+
+		// 删除申请及其问题回答、附件与面试官分配，释放面试预约
+		var attachments []models.Attachment
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("application_id = ?", application.ID).Delete(&models.ApplicationAnswer{}).Error; err != nil {
+				return err
+			}
+			if attachments, err = deleteAttachmentRecords(tx, "application_id = ?", application.ID); err != nil {
+				return err
+			}
+			if err := releaseUserBookings(tx, "user_id = ? AND season_id = ?", application.UserID, application.SeasonID); err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ? AND season_id = ?", application.UserID, application.SeasonID).Delete(&models.Assignment{}).Error; err != nil {
+				return err
+			}
+			if err := deleteScorecards(tx, "user_id = ? AND season_id = ?", application.UserID, application.SeasonID); err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ? AND season_id = ?", application.UserID, application.SeasonID).Delete(&models.BlindReveal{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&application).Error
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
+		purgeAttachmentFiles(store, attachments)
 
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	}
@@ -0,0 +1,566 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"slices"
+	"sort"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// interviewerLoad 面试官在一个招新季的分配情况
+type interviewerLoad struct {
+	// Open 面试者在该方向尚未到达终态的分配数量，自动分配按它计算容量
+	Open        int
+	Total       int
+	ByDirection map[string]int
+}
+
+// assignmentStatuses 查询分配对应的面试者方向状态，键为 用户ID/方向
+func assignmentStatuses(db *gorm.DB, seasonID uuid.UUID, assignments []models.Assignment) (map[string]string, error) {
+	statuses := make(map[string]string)
+	if len(assignments) == 0 {
+		return statuses, nil
+	}
+	userIds := make([]uuid.UUID, 0, len(assignments))
+	for _, assignment := range assignments {
+		userIds = append(userIds, assignment.UserID)
+	}
+	var directionStatuses []models.DirectionStatus
+	if err := db.Where("season_id = ? AND user_id IN ?", seasonID, userIds).Find(&directionStatuses).Error; err != nil {
+		return nil, err
+	}
+	for _, directionStatus := range directionStatuses {
+		statuses[directionStatus.UserID.String()+"/"+directionStatus.Direction] = directionStatus.Status
+	}
+	return statuses, nil
+}
+
+// loadInterviewerLoads 统计招新季中各面试官的分配情况
+func loadInterviewerLoads(db *gorm.DB, seasonID uuid.UUID) (map[uuid.UUID]*interviewerLoad, error) {
+	var assignments []models.Assignment
+	if err := db.Where("season_id = ?", seasonID).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	statuses, err := assignmentStatuses(db, seasonID, assignments)
+	if err != nil {
+		return nil, err
+	}
+	terminal := terminalStatuses(db)
+
+	loads := make(map[uuid.UUID]*interviewerLoad)
+	for _, assignment := range assignments {
+		load, exists := loads[assignment.InterviewerID]
+		if !exists {
+			load = &interviewerLoad{ByDirection: make(map[string]int)}
+			loads[assignment.InterviewerID] = load
+		}
+		load.Total++
+		status, exists := statuses[assignment.UserID.String()+"/"+assignment.Direction]
+		if exists && !terminal[status] {
+			load.Open++
+			load.ByDirection[assignment.Direction]++
+		}
+	}
+	return loads, nil
+}
+
+// loadInterviewers 查询所有面试官
+func loadInterviewers(db *gorm.DB) ([]models.User, error) {
+	var interviewers []models.User
+	err := db.Select("uuid", "email", "nickname", "directions", "assignment_capacity").
+		Where("role = ?", "interviewer").Order("created_at ASC").Find(&interviewers).Error
+	return interviewers, err
+}
+
+// assignmentCandidate 待分配的面试者方向
+type assignmentCandidate struct {
+	UserID    uuid.UUID
+	Direction string
+}
+
+// pickInterviewer 在负责该方向、仍有容量且没有利益冲突的面试官中选择负载比例最低的一个
+func pickInterviewer(interviewers []models.User, loads map[uuid.UUID]*interviewerLoad, conflicts map[string]bool, candidate assignmentCandidate) *models.User {
+	var picked *models.User
+	var pickedOpen int
+	for i := range interviewers {
+		interviewer := &interviewers[i]
+		if interviewer.AssignmentCapacity <= 0 || !slices.Contains(parseJSONList(interviewer.Directions), candidate.Direction) {
+			continue
+		}
+		if conflicts[interviewer.UUID.String()+"/"+candidate.UserID.String()] {
+			continue
+		}
+		open := 0
+		if load, exists := loads[interviewer.UUID]; exists {
+			open = load.Open
+		}
+		if open >= interviewer.AssignmentCapacity {
+			continue
+		}
+		// 比较 open/capacity，相同时选择分配较少的
+		if picked == nil ||
+			open*picked.AssignmentCapacity < pickedOpen*interviewer.AssignmentCapacity ||
+			(open*picked.AssignmentCapacity == pickedOpen*interviewer.AssignmentCapacity && open < pickedOpen) {
+			picked = interviewer
+			pickedOpen = open
+		}
+	}
+	return picked
+}
+
+// AutoAssignRequest 自动分配请求
+type AutoAssignRequest struct {
+	Directions []string `json:"directions"`
+	DryRun     bool     `json:"dryRun"`
+}
+
+// AutoAssign 将当前招新季未分配的面试者按方向分配给面试官（管理员）
+// 只分配尚未到达终态的方向，按申请提交顺序依次分配给负责该方向、没有利益冲突且负载比例最低的面试官
+func AutoAssign(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AutoAssignRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		assigned := make([]gin.H, 0)
+		unassigned := make([]gin.H, 0)
+		err = db.Transaction(func(tx *gorm.DB) error {
+			interviewers, err := loadInterviewers(tx)
+			if err != nil {
+				return err
+			}
+			loads, err := loadInterviewerLoads(tx, seasonID)
+			if err != nil {
+				return err
+			}
+			conflicts, err := loadConflictPairs(tx)
+			if err != nil {
+				return err
+			}
+
+			// 当前招新季已提交申请、方向未到达终态且尚未分配的面试者
+			var applications []models.Application
+			if err := tx.Select("user_id", "created_at").Where("season_id = ?", seasonID).Order("created_at ASC").Find(&applications).Error; err != nil {
+				return err
+			}
+			var directionStatuses []models.DirectionStatus
+			if err := tx.Where("season_id = ?", seasonID).Find(&directionStatuses).Error; err != nil {
+				return err
+			}
+			var existing []models.Assignment
+			if err := tx.Select("user_id", "direction").Where("season_id = ?", seasonID).Find(&existing).Error; err != nil {
+				return err
+			}
+			assignedKeys := make(map[string]bool)
+			for _, assignment := range existing {
+				assignedKeys[assignment.UserID.String()+"/"+assignment.Direction] = true
+			}
+			terminal := terminalStatuses(tx)
+			statusesByUser := make(map[uuid.UUID][]models.DirectionStatus)
+			for _, directionStatus := range directionStatuses {
+				statusesByUser[directionStatus.UserID] = append(statusesByUser[directionStatus.UserID], directionStatus)
+			}
+
+			candidates := make([]assignmentCandidate, 0)
+			for _, application := range applications {
+				userStatuses := statusesByUser[application.UserID]
+				sort.Slice(userStatuses, func(i, j int) bool { return userStatuses[i].Direction < userStatuses[j].Direction })
+				for _, directionStatus := range userStatuses {
+					if terminal[directionStatus.Status] || assignedKeys[application.UserID.String()+"/"+directionStatus.Direction] {
+						continue
+					}
+					if len(req.Directions) > 0 && !slices.Contains(req.Directions, directionStatus.Direction) {
+						continue
+					}
+					candidates = append(candidates, assignmentCandidate{UserID: application.UserID, Direction: directionStatus.Direction})
+				}
+			}
+
+			for _, candidate := range candidates {
+				interviewer := pickInterviewer(interviewers, loads, conflicts, candidate)
+				if interviewer == nil {
+					unassigned = append(unassigned, gin.H{
+						"userId":    candidate.UserID.String(),
+						"direction": candidate.Direction,
+					})
+					continue
+				}
+
+				if !req.DryRun {
+					assignmentUUID, _ := uuid.NewUUID()
+					if err := tx.Create(&models.Assignment{
+						UUID:          assignmentUUID,
+						SeasonID:      seasonID,
+						UserID:        candidate.UserID,
+						Direction:     candidate.Direction,
+						InterviewerID: interviewer.UUID,
+					}).Error; err != nil {
+						return err
+					}
+				}
+
+				load, exists := loads[interviewer.UUID]
+				if !exists {
+					load = &interviewerLoad{ByDirection: make(map[string]int)}
+					loads[interviewer.UUID] = load
+				}
+				load.Open++
+				load.Total++
+				load.ByDirection[candidate.Direction]++
+				assigned = append(assigned, gin.H{
+					"userId":        candidate.UserID.String(),
+					"direction":     candidate.Direction,
+					"interviewerId": interviewer.UUID.String(),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"dryRun":     req.DryRun,
+				"assigned":   assigned,
+				"unassigned": unassigned,
+			},
+		})
+	}
+}
+
+// AssignInterviewerRequest 手动分配请求
+type AssignInterviewerRequest struct {
+	UserID        string `json:"userId" binding:"required"`
+	Direction     string `json:"direction" binding:"required"`
+	InterviewerID string `json:"interviewerId" binding:"required"`
+	// Force 为 true 时允许超出面试官容量
+	Force bool `json:"force"`
+}
+
+// AssignInterviewer 手动分配或改派面试官（面试官）
+func AssignInterviewer(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AssignInterviewerRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		actorUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		userUUID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		interviewerUUID, err := uuid.Parse(req.InterviewerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var directionStatus models.DirectionStatus
+		if err := db.Where("user_id = ? AND season_id = ? AND direction = ?", userUUID, seasonID, req.Direction).First(&directionStatus).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试者没有申请该方向"})
+			return
+		}
+
+		var interviewer models.User
+		if err := db.Where("uuid = ? AND role = ?", interviewerUUID, "interviewer").First(&interviewer).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试官不存在"})
+			return
+		}
+		if !slices.Contains(parseJSONList(interviewer.Directions), req.Direction) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "面试官不负责该方向"})
+			return
+		}
+		if hasConflict(db, interviewerUUID, userUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "该面试官已声明与面试者存在利益冲突"})
+			return
+		}
+
+		var assignment models.Assignment
+		exists := db.Where("season_id = ? AND user_id = ? AND direction = ?", seasonID, userUUID, req.Direction).First(&assignment).Error == nil
+		if exists && assignment.InterviewerID == interviewerUUID {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "data": gin.H{"id": assignment.UUID.String()}})
+			return
+		}
+
+		if !req.Force && !terminalStatuses(db)[directionStatus.Status] {
+			loads, err := loadInterviewerLoads(db, seasonID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			// 没有分配记录的面试官 Open 为 0，上限为 0 时同样不能再分配
+			open := 0
+			if load, found := loads[interviewerUUID]; found {
+				open = load.Open
+			}
+			if open >= interviewer.AssignmentCapacity {
+				c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "面试官已达到分配上限"})
+				return
+			}
+		}
+
+		if exists {
+			updates := map[string]interface{}{
+				"interviewer_id": interviewerUUID,
+				"assigned_by":    actorUUID,
+			}
+			if err := db.Model(&assignment).Updates(updates).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+		} else {
+			assignmentUUID, _ := uuid.NewUUID()
+			assignment = models.Assignment{
+				UUID:          assignmentUUID,
+				SeasonID:      seasonID,
+				UserID:        userUUID,
+				Direction:     req.Direction,
+				InterviewerID: interviewerUUID,
+				AssignedBy:    &actorUUID,
+			}
+			if err := db.Create(&assignment).Error; err != nil {
+				c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "该方向已分配，请刷新后重试"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": assignment.UUID.String(),
+			},
+		})
+	}
+}
+
+// DeleteAssignment 取消分配（面试官）
+func DeleteAssignment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assignmentUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		result := db.Where("uuid = ?", assignmentUUID).Delete(&models.Assignment{})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "分配不存在"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// GetAssignments 获取分配列表（面试官）
+func GetAssignments(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		tx := db.Where("season_id = ?", season.UUID)
+		if c.Query("mine") == "true" {
+			tx = tx.Where("interviewer_id = ?", userUUID)
+		} else if interviewerID := c.Query("interviewerId"); interviewerID != "" {
+			interviewerUUID, err := uuid.Parse(interviewerID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+				return
+			}
+			tx = tx.Where("interviewer_id = ?", interviewerUUID)
+		}
+		if candidateID := c.Query("userId"); candidateID != "" {
+			candidateUUID, err := uuid.Parse(candidateID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+				return
+			}
+			tx = tx.Where("user_id = ?", candidateUUID)
+		}
+		if directions := splitQueryList(c.QueryArray("direction")); len(directions) > 0 {
+			tx = tx.Where("direction IN ?", directions)
+		}
+
+		var assignments []models.Assignment
+		if err := tx.Order("created_at ASC").Find(&assignments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		statuses, err := assignmentStatuses(db, season.UUID, assignments)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		userIds := make([]uuid.UUID, 0, len(assignments)*2)
+		for _, assignment := range assignments {
+			userIds = append(userIds, assignment.UserID, assignment.InterviewerID)
+			if assignment.AssignedBy != nil {
+				userIds = append(userIds, *assignment.AssignedBy)
+			}
+		}
+		userNames := loadUserNames(db, userIds)
+		// 匿名评审轮次中的面试者显示化名
+		loadSeasonBlindView(db, c, season.UUID).maskNames(db, userNames)
+
+		items := make([]gin.H, 0, len(assignments))
+		for _, assignment := range assignments {
+			item := gin.H{
+				"id":              assignment.UUID.String(),
+				"userId":          assignment.UserID.String(),
+				"userName":        template.HTMLEscapeString(userNames[assignment.UserID.String()]),
+				"direction":       assignment.Direction,
+				"status":          statuses[assignment.UserID.String()+"/"+assignment.Direction],
+				"interviewerId":   assignment.InterviewerID.String(),
+				"interviewerName": template.HTMLEscapeString(userNames[assignment.InterviewerID.String()]),
+				"auto":            assignment.AssignedBy == nil,
+				"assignedByName":  "",
+				"createdAt":       assignment.CreatedAt,
+				"updatedAt":       assignment.UpdatedAt,
+			}
+			if assignment.AssignedBy != nil {
+				item["assignedByName"] = template.HTMLEscapeString(userNames[assignment.AssignedBy.String()])
+			}
+			items = append(items, item)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// GetAssignmentLoad 获取各面试官的分配负载（面试官）
+func GetAssignmentLoad(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		interviewers, err := loadInterviewers(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		loads, err := loadInterviewerLoads(db, season.UUID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		items := make([]gin.H, 0, len(interviewers))
+		for _, interviewer := range interviewers {
+			load, exists := loads[interviewer.UUID]
+			if !exists {
+				load = &interviewerLoad{ByDirection: make(map[string]int)}
+			}
+			name := interviewer.Email
+			if interviewer.Nickname != nil && *interviewer.Nickname != "" {
+				name = *interviewer.Nickname
+			}
+			items = append(items, gin.H{
+				"interviewerId":   interviewer.UUID.String(),
+				"interviewerName": template.HTMLEscapeString(name),
+				"directions":      parseJSONList(interviewer.Directions),
+				"capacity":        interviewer.AssignmentCapacity,
+				"open":            load.Open,
+				"total":           load.Total,
+				"remaining":       max(interviewer.AssignmentCapacity-load.Open, 0),
+				"byDirection":     load.ByDirection,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// AssignmentCapacityRequest 设置面试官分配上限请求
+type AssignmentCapacityRequest struct {
+	Capacity *int `json:"capacity" binding:"required,min=0,max=1000"`
+}
+
+// SetAssignmentCapacity 设置面试官同时负责的面试者数量上限（管理员），为 0 时不参与自动分配
+func SetAssignmentCapacity(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		interviewerUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req AssignmentCapacityRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		result := db.Model(&models.User{}).Where("uuid = ? AND role = ?", interviewerUUID, "interviewer").Update("assignment_capacity", *req.Capacity)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			db.Model(&models.User{}).Where("uuid = ? AND role = ?", interviewerUUID, "interviewer").Count(&count)
+			if count == 0 {
+				c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试官不存在"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
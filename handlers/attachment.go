@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"xdsec-join-2026/models"
+	"xdsec-join-2026/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// attachmentTypes 允许上传的扩展名及其对应的文件类型（按文件内容识别）
+var attachmentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".zip":  "application/zip",
+	".txt":  "text/plain",
+	".md":   "text/plain",
+}
+
+// maxAttachmentsPerParent 每个申请或任务最多的附件数
+const maxAttachmentsPerParent = 10
+
+// DefaultMaxUploadSize 默认的单个附件大小上限
+const DefaultMaxUploadSize = 10 << 20
+
+// errAttachmentRejected 附件未通过校验
+var errAttachmentRejected = errors.New("attachment rejected")
+
+// errAttachmentLimit 附件数量已达上限
+var errAttachmentLimit = errors.New("attachment limit reached")
+
+// attachmentData 构建附件的返回数据
+func attachmentData(attachment models.Attachment) gin.H {
+	return gin.H{
+		"id":          attachment.UUID.String(),
+		"fileName":    template.HTMLEscapeString(attachment.FileName),
+		"contentType": attachment.ContentType,
+		"size":        attachment.Size,
+		"createdAt":   attachment.CreatedAt,
+	}
+}
+
+// attachmentList 构建附件列表的返回数据
+func attachmentList(attachments []models.Attachment) []gin.H {
+	items := make([]gin.H, 0, len(attachments))
+	for _, attachment := range attachments {
+		items = append(items, attachmentData(attachment))
+	}
+	return items
+}
+
+// loadApplicationAttachments 查询申请的附件
+func loadApplicationAttachments(db *gorm.DB, applicationID uint) []models.Attachment {
+	var attachments []models.Attachment
+	db.Where("application_id = ?", applicationID).Order("created_at ASC").Find(&attachments)
+	return attachments
+}
+
+// loadTaskAttachments 批量查询任务的附件
+func loadTaskAttachments(db *gorm.DB, taskIds []uuid.UUID) map[uuid.UUID][]models.Attachment {
+	result := make(map[uuid.UUID][]models.Attachment)
+	if len(taskIds) == 0 {
+		return result
+	}
+	var attachments []models.Attachment
+	if err := db.Where("task_id IN ?", taskIds).Order("created_at ASC").Find(&attachments).Error; err != nil {
+		return result
+	}
+	for _, attachment := range attachments {
+		result[*attachment.TaskID] = append(result[*attachment.TaskID], attachment)
+	}
+	return result
+}
+
+// receiveAttachment 读取并校验上传的文件，通过后写入存储
+// 文件类型按内容识别，必须与扩展名一致；返回的 message 非空时表示校验失败
+func receiveAttachment(c *gin.Context, store storage.Storage, maxSize int64, attachment *models.Attachment) (int, string, error) {
+	// 限制请求体大小，多留出表单字段的空间
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return http.StatusRequestEntityTooLarge, "文件过大", errAttachmentRejected
+		}
+		return http.StatusBadRequest, "请选择要上传的文件", errAttachmentRejected
+	}
+	if header.Size > maxSize {
+		return http.StatusRequestEntityTooLarge, "文件过大", errAttachmentRejected
+	}
+	if header.Size == 0 {
+		return http.StatusBadRequest, "文件为空", errAttachmentRejected
+	}
+
+	fileName := filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	if !utf8.ValidString(fileName) || utf8.RuneCountInString(fileName) > 255 {
+		return http.StatusBadRequest, "文件名不合法", errAttachmentRejected
+	}
+	expected, allowed := attachmentTypes[strings.ToLower(filepath.Ext(fileName))]
+	if !allowed {
+		return http.StatusBadRequest, "不支持的文件类型", errAttachmentRejected
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return http.StatusInternalServerError, "服务器错误", err
+	}
+	defer file.Close()
+
+	// 按文件头识别类型，防止伪造扩展名
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return http.StatusInternalServerError, "服务器错误", err
+	}
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if detected != expected {
+		return http.StatusBadRequest, "文件内容与扩展名不符", errAttachmentRejected
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return http.StatusInternalServerError, "服务器错误", err
+	}
+
+	attachmentUUID, _ := uuid.NewUUID()
+	attachment.UUID = attachmentUUID
+	attachment.FileName = fileName
+	attachment.ContentType = expected
+	attachment.Size = header.Size
+	if err := store.Save(attachmentUUID.String(), file); err != nil {
+		return http.StatusInternalServerError, "保存文件失败", err
+	}
+	return http.StatusOK, "", nil
+}
+
+// attachmentParent 附件所属的申请或任务
+type attachmentParent struct {
+	// model 与 key 用于锁定所属记录，countQuery 用于统计其附件数
+	model      interface{}
+	key        string
+	countQuery string
+	value      interface{}
+	// afterCreate 非空时在写入附件记录的事务中执行，此时 model 为已锁定的所属记录
+	afterCreate func(tx *gorm.DB) error
+}
+
+// countParentAttachments 统计所属记录已有的附件数
+func countParentAttachments(tx *gorm.DB, parent attachmentParent) (int64, error) {
+	var count int64
+	err := tx.Model(&models.Attachment{}).Where(parent.countQuery, parent.value).Count(&count).Error
+	return count, err
+}
+
+// createAttachment 保存上传的附件并记录，记录失败时清理已写入的文件
+// 接收文件前先检查一次数量以便尽早拒绝，写入记录时锁定所属记录后再次统计，避免并发上传超出上限
+func createAttachment(c *gin.Context, db *gorm.DB, store storage.Storage, maxSize int64, attachment models.Attachment, parent attachmentParent) {
+	count, err := countParentAttachments(db, parent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+		return
+	}
+	if count >= maxAttachmentsPerParent {
+		c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "附件数量已达上限"})
+		return
+	}
+
+	code, message, err := receiveAttachment(c, store, maxSize, &attachment)
+	if err != nil {
+		if !errors.Is(err, errAttachmentRejected) {
+			log.Printf("保存附件失败: %v", err)
+		}
+		c.JSON(code, gin.H{"ok": false, "message": message})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(parent.key, parent.value).First(parent.model).Error; err != nil {
+			return err
+		}
+		count, err := countParentAttachments(tx, parent)
+		if err != nil {
+			return err
+		}
+		if count >= maxAttachmentsPerParent {
+			return errAttachmentLimit
+		}
+		if err := tx.Create(&attachment).Error; err != nil {
+			return err
+		}
+		if parent.afterCreate != nil {
+			return parent.afterCreate(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		store.Delete(attachment.UUID.String())
+		if errors.Is(err, errAttachmentLimit) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "附件数量已达上限"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ok":   true,
+		"data": attachmentData(attachment),
+	})
+}
+
+// deleteAttachmentRecords 在事务中删除符合条件的附件记录，返回被删除的附件，提交后再用 purgeAttachmentFiles 删除文件
+func deleteAttachmentRecords(tx *gorm.DB, query string, args ...interface{}) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if err := tx.Where(query, args...).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	if len(attachments) == 0 {
+		return attachments, nil
+	}
+	if err := tx.Where(query, args...).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// purgeAttachmentFiles 删除附件文件，失败时只记录日志
+func purgeAttachmentFiles(store storage.Storage, attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		if err := store.Delete(attachment.UUID.String()); err != nil {
+			log.Printf("删除附件文件 %s 失败: %v", attachment.UUID, err)
+		}
+	}
+}
+
+// UploadApplicationAttachment 为自己当前招新季的申请上传附件
+func UploadApplicationAttachment(db *gorm.DB, store storage.Storage, maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		season, err := currentSeason(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var application models.Application
+		if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).First(&application).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "请先提交申请"})
+			return
+		}
+
+		// 上传附件视为修改申请，需要在开放时间内
+		windowErr, err := checkApplicationWindow(db, season, userUUID, parseJSONList(application.Directions), time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if windowErr != nil {
+			c.JSON(http.StatusForbidden, windowErrorBody(windowErr))
+			return
+		}
+
+		applicationID := application.ID
+		createAttachment(c, db, store, maxSize, models.Attachment{
+			OwnerID:       userUUID,
+			ApplicationID: &applicationID,
+		}, attachmentParent{model: &models.Application{}, key: "id = ?", countQuery: "application_id = ?", value: applicationID})
+	}
+}
+
+// UploadTaskAttachment 为自己的任务上传报告附件，上传后需要重新批阅
+func UploadTaskAttachment(db *gorm.DB, store storage.Storage, maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		taskUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var task models.Task
+		if err := db.Where("uuid = ?", taskUUID).First(&task).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "任务不存在"})
+			return
+		}
+
+		if task.TargetUserId != userUUID {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "无权限"})
+			return
+		}
+
+		if task.Status == "accepted" {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "任务已通过，无法再提交"})
+			return
+		}
+		if taskLate(task, time.Now()) && task.LatePolicy == "reject" {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "已超过截止时间，无法提交"})
+			return
+		}
+
+		// 已批阅的报告补充附件后需要重新批阅，附件写入成功后才重置批阅状态
+		locked := &models.Task{}
+		createAttachment(c, db, store, maxSize, models.Attachment{
+			OwnerID: userUUID,
+			TaskID:  &taskUUID,
+		}, attachmentParent{model: locked, key: "uuid = ?", countQuery: "task_id = ?", value: taskUUID, afterCreate: func(tx *gorm.DB) error {
+			if locked.Status != "reviewed" {
+				return nil
+			}
+			return tx.Model(locked).Updates(map[string]interface{}{"status": "submitted", "reviewed_by": nil, "reviewed_at": nil}).Error
+		}})
+	}
+}
+
+// loadAccessibleAttachment 查询附件并检查权限，只有上传者与面试官可以访问
+func loadAccessibleAttachment(db *gorm.DB, c *gin.Context) (*models.Attachment, int, string) {
+	attachmentUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, http.StatusBadRequest, "参数校验失败"
+	}
+
+	userUUID, ok := GetCurrentUserUUID(c)
+	if !ok {
+		return nil, http.StatusUnauthorized, "未登录"
+	}
+
+	var attachment models.Attachment
+	if err := db.Where("uuid = ?", attachmentUUID).First(&attachment).Error; err != nil {
+		return nil, http.StatusNotFound, "附件不存在"
+	}
+
+	// 对无权访问的用户同样返回不存在，避免泄露附件是否存在
+	if attachment.OwnerID != userUUID && GetCurrentUserRole(c) != "interviewer" {
+		return nil, http.StatusNotFound, "附件不存在"
+	}
+	return &attachment, http.StatusOK, ""
+}
+
+// DownloadAttachment 下载附件
+func DownloadAttachment(db *gorm.DB, store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		attachment, code, message := loadAccessibleAttachment(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		reader, err := store.Open(attachment.UUID.String())
+		if err != nil {
+			log.Printf("读取附件 %s 失败: %v", attachment.UUID, err)
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "附件文件不存在"})
+			return
+		}
+		defer reader.Close()
+
+		// 始终作为下载返回，不让浏览器按内容渲染
+		headers := map[string]string{
+			"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+			"X-Content-Type-Options": "nosniff",
+		}
+		c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, headers)
+	}
+}
+
+// DeleteAttachment 删除自己上传的附件
+func DeleteAttachment(db *gorm.DB, store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		attachment, code, message := loadAccessibleAttachment(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		userUUID, _ := GetCurrentUserUUID(c)
+		if attachment.OwnerID != userUUID {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "只能删除自己上传的附件"})
+			return
+		}
+
+		// 删除申请附件视为修改申请，需要在开放时间内
+		if attachment.ApplicationID != nil {
+			var application models.Application
+			var season models.Season
+			if err := db.Where("id = ?", *attachment.ApplicationID).First(&application).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			if err := db.Where("uuid = ?", application.SeasonID).First(&season).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			windowErr, err := checkApplicationWindow(db, &season, userUUID, parseJSONList(application.Directions), time.Now())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			if windowErr != nil {
+				c.JSON(http.StatusForbidden, windowErrorBody(windowErr))
+				return
+			}
+		}
+
+		// 任务附件在任务通过后不能再删除，锁定任务后检查，避免与批阅并发
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if attachment.TaskID != nil {
+				var task models.Task
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", *attachment.TaskID).First(&task).Error; err != nil {
+					return err
+				}
+				if task.Status == "accepted" {
+					return errAttachmentRejected
+				}
+			}
+			return tx.Delete(attachment).Error
+		})
+		if errors.Is(err, errAttachmentRejected) {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "任务已通过，无法删除附件"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		purgeAttachmentFiles(store, []models.Attachment{*attachment})
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
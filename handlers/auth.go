@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strings"
 
 	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // AuthMiddleware Session认证中间件
@@ -74,6 +78,42 @@ func RequireInterviewer() gin.HandlerFunc {
 	}
 }
 
+// RequireAdmin 要求管理员权限的中间件（管理员标记存储在数据库中，需实时查询）
+func RequireAdmin(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok || !IsAdmin(db, userUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "需要管理员权限"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// IsAdmin 检查用户是否为管理员
+func IsAdmin(db *gorm.DB, userUUID uuid.UUID) bool {
+	var user models.User
+	if err := db.Select("uuid", "is_admin").Where("uuid = ?", userUUID).First(&user).Error; err != nil {
+		return false
+	}
+	return user.IsAdmin
+}
+
+// SeedAdmins 将配置中列出的邮箱对应的用户标记为管理员（逗号分隔）
+func SeedAdmins(db *gorm.DB, emails string) {
+	list := splitQueryList([]string{emails})
+	if len(list) == 0 {
+		return
+	}
+	for i := range list {
+		list[i] = strings.ToLower(list[i])
+	}
+	if err := db.Model(&models.User{}).Where("LOWER(email) IN ?", list).Update("is_admin", true).Error; err != nil {
+		log.Printf("设置管理员失败: %v", err)
+	}
+}
+
 // GetCurrentUserUUID 获取当前用户UUID
 func GetCurrentUserUUID(c *gin.Context) (uuid.UUID, bool) {
 	userUUID, exists := c.Get("user_uuid")
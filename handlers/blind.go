@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// blindView 匿名评审：面试者当前所处轮次开启匿名且未被管理员解除时，对面试官隐藏身份信息
+type blindView struct {
+	seasonID     uuid.UUID
+	blindRounds  map[int]bool
+	statusRounds map[string]int
+	revealed     map[string]bool
+}
+
+// loadBlindView 加载当前招新季的匿名评审设置，管理员或没有匿名轮次时返回 nil
+func loadBlindView(db *gorm.DB, c *gin.Context, seasonID uuid.UUID) *blindView {
+	userUUID, _ := GetCurrentUserUUID(c)
+	return loadViewerBlindView(db, userUUID, GetCurrentUserRole(c), seasonID)
+}
+
+// loadViewerBlindView 按查看者的身份加载匿名评审设置，用于没有登录上下文的场景（如日历订阅）
+func loadViewerBlindView(db *gorm.DB, viewerUUID uuid.UUID, role string, seasonID uuid.UUID) *blindView {
+	if role != "interviewer" || IsAdmin(db, viewerUUID) {
+		return nil
+	}
+
+	var rounds []models.Round
+	db.Where("blind = ?", true).Find(&rounds)
+	if len(rounds) == 0 {
+		return nil
+	}
+
+	view := &blindView{
+		seasonID:     seasonID,
+		blindRounds:  make(map[int]bool, len(rounds)),
+		statusRounds: make(map[string]int),
+		revealed:     make(map[string]bool),
+	}
+	for _, round := range rounds {
+		view.blindRounds[round.Number] = true
+	}
+	definitions, _ := loadStatusDefinitions(db)
+	for _, definition := range definitions {
+		view.statusRounds[definition.Key] = definition.Round
+	}
+	var reveals []models.BlindReveal
+	db.Where("season_id = ?", seasonID).Find(&reveals)
+	for _, reveal := range reveals {
+		view.revealed[revealKey(reveal.UserID, reveal.Round)] = true
+	}
+	return view
+}
+
+// loadSeasonBlindView 只对当前招新季加载匿名评审设置，往届招新季返回 nil
+func loadSeasonBlindView(db *gorm.DB, c *gin.Context, seasonID uuid.UUID) *blindView {
+	if currentID, err := currentSeasonID(db); err != nil || currentID != seasonID {
+		return nil
+	}
+	return loadBlindView(db, c, seasonID)
+}
+
+// maskNames 将 loadUserNames 结果中需要隐藏身份的面试者姓名替换为化名，返回被隐藏的用户
+func (v *blindView) maskNames(db *gorm.DB, names map[string]string) map[uuid.UUID]bool {
+	result := make(map[uuid.UUID]bool)
+	if v == nil || len(names) == 0 {
+		return result
+	}
+	userIds := make([]uuid.UUID, 0, len(names))
+	for id := range names {
+		if userUUID, err := uuid.Parse(id); err == nil {
+			userIds = append(userIds, userUUID)
+		}
+	}
+	var users []models.User
+	db.Select("uuid", "role", "status").Where("uuid IN ?", userIds).Find(&users)
+	for _, user := range users {
+		if v.masked(user) {
+			names[user.UUID.String()] = pseudonym(v.seasonID, user.UUID)
+			result[user.UUID] = true
+		}
+	}
+	return result
+}
+
+// unmaskedScope 返回只匹配无需隐藏身份的用户的查询条件，没有需要隐藏的面试者时返回 nil
+func (v *blindView) unmaskedScope(db *gorm.DB) *gorm.DB {
+	if v == nil {
+		return nil
+	}
+	roundStatuses := make(map[int][]string)
+	for status, round := range v.statusRounds {
+		if v.blindRounds[round] {
+			roundStatuses[round] = append(roundStatuses[round], status)
+		}
+	}
+	if len(roundStatuses) == 0 {
+		return nil
+	}
+
+	rounds := make([]int, 0, len(roundStatuses))
+	blindStatuses := make([]string, 0, len(v.statusRounds))
+	for round, statuses := range roundStatuses {
+		slices.Sort(statuses)
+		rounds = append(rounds, round)
+		blindStatuses = append(blindStatuses, statuses...)
+	}
+	slices.Sort(rounds)
+	slices.Sort(blindStatuses)
+
+	// 非面试者、不在匿名轮次或已被解除该轮匿名的用户
+	scope := db.Where("role <> ?", "interviewee").Or("status NOT IN ?", blindStatuses)
+	for _, round := range rounds {
+		revealed := db.Model(&models.BlindReveal{}).Select("user_id").Where("season_id = ? AND round = ?", v.seasonID, round)
+		scope = scope.Or(db.Where("status IN ?", roundStatuses[round]).Where("uuid IN (?)", revealed))
+	}
+	return scope
+}
+
+// revealKey 解除匿名记录的索引
+func revealKey(userUUID uuid.UUID, round int) string {
+	return fmt.Sprintf("%s/%d", userUUID, round)
+}
+
+// masked 按面试者的汇总状态所属轮次判断是否需要隐藏身份
+func (v *blindView) masked(user models.User) bool {
+	if v == nil || user.Role != "interviewee" {
+		return false
+	}
+	round := v.statusRounds[user.Status]
+	return v.blindRounds[round] && !v.revealed[revealKey(user.UUID, round)]
+}
+
+// pseudonym 面试者在某个招新季内固定不变的化名
+func pseudonym(seasonID, userUUID uuid.UUID) string {
+	sum := sha256.Sum256([]byte(seasonID.String() + "/" + userUUID.String()))
+	return "候选人-" + strings.ToUpper(hex.EncodeToString(sum[:])[:6])
+}
+
+// blindFields 匿名评审中隐藏的申请字段
+var blindFields = []string{"realName", "phone", "gender", "department", "studentId"}
+
+// maskApplication 隐藏申请中的身份信息，简历与方向等保持可见
+func maskApplication(application models.Application) models.Application {
+	application.RealName = ""
+	application.Phone = ""
+	application.Gender = ""
+	application.Department = ""
+	application.StudentId = ""
+	// 入学年份由学号得出，一并隐藏
+	application.EnrollmentYear = 0
+	return application
+}
+
+// maskApplicationData 隐藏申请返回数据中的身份信息
+func maskApplicationData(appData gin.H) {
+	for _, key := range blindFields {
+		appData[key] = ""
+	}
+	if _, exists := appData["enrollmentYear"]; exists {
+		appData["enrollmentYear"] = 0
+	}
+}
+
+// RevealCandidateRequest 解除匿名请求
+type RevealCandidateRequest struct {
+	Round int `json:"round" binding:"min=1"`
+}
+
+// RevealCandidate 解除面试者某一轮的匿名（管理员），该轮已有评分表后才能解除
+func RevealCandidate(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req RevealCandidateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		adminUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		if !roundExists(db, req.Round) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "面试轮次不存在"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("uuid = ?", userUUID).First(&user).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "用户不存在"})
+			return
+		}
+
+		var count int64
+		if err := db.Model(&models.Scorecard{}).Where("season_id = ? AND user_id = ? AND round = ?", seasonID, user.UUID, req.Round).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "该轮次尚未提交评分，无法解除匿名"})
+			return
+		}
+
+		reveal := models.BlindReveal{SeasonID: seasonID, UserID: user.UUID, Round: req.Round}
+		if err := db.Where(&reveal).Attrs(models.BlindReveal{RevealedBy: adminUUID}).FirstOrCreate(&reveal).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
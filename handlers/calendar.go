@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/ical"
+	"xdsec-join-2026/models"
+	"xdsec-join-2026/smtp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// calendarUIDDomain 事件 UID 的域名部分，UID 一经发布不能修改，否则日历应用会出现重复事件
+const calendarUIDDomain = "join.xdsec"
+
+// calendarFeedPath 日历订阅链接（相对于接口根路径）
+func calendarFeedPath(token string) string {
+	return "/calendar/" + token + ".ics"
+}
+
+// slotDescription 面试事件的描述
+func slotDescription(slot models.InterviewSlot, interviewerName string, includeLink bool) string {
+	lines := []string{"方向：" + slot.Direction, "面试官：" + interviewerName}
+	if includeLink && slot.OnlineLink != "" {
+		lines = append(lines, "线上链接："+slot.OnlineLink)
+	}
+	if slot.Note != "" {
+		lines = append(lines, "备注："+slot.Note)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slotLocation 面试事件的地点，没有线下地点时使用线上链接
+func slotLocation(slot models.InterviewSlot, includeLink bool) string {
+	if slot.Location != "" || !includeLink {
+		return slot.Location
+	}
+	return slot.OnlineLink
+}
+
+// bookingEvent 面试者视角的面试事件，每个预约一个事件，改约后原事件标记为取消
+func bookingEvent(booking models.SlotBooking, slot models.InterviewSlot, interviewerName string) ical.Event {
+	active := booking.Status == "booked" && slot.CancelledAt == nil
+	event := ical.Event{
+		UID:          fmt.Sprintf("booking-%s@%s", booking.UUID, calendarUIDDomain),
+		Sequence:     slot.Sequence,
+		Start:        slot.StartsAt,
+		End:          slot.StartsAt.Add(time.Duration(slot.Duration) * time.Minute),
+		Summary:      fmt.Sprintf("XDSEC 招新面试（%s）", slot.Direction),
+		Description:  slotDescription(slot, interviewerName, active),
+		Location:     slotLocation(slot, active),
+		Cancelled:    !active,
+		LastModified: slot.UpdatedAt,
+	}
+	if active {
+		event.URL = slot.OnlineLink
+	}
+	// 取消预约不会修改时间段，序号需要额外递增
+	if booking.Status == "cancelled" {
+		event.Sequence++
+		if booking.UpdatedAt.After(event.LastModified) {
+			event.LastModified = booking.UpdatedAt
+		}
+	}
+	return event
+}
+
+// taskDeadlineEvent 任务截止时间事件
+func taskDeadlineEvent(task models.Task, summary string) ical.Event {
+	return ical.Event{
+		UID:          fmt.Sprintf("task-%s@%s", task.UUID, calendarUIDDomain),
+		Start:        *task.DueAt,
+		Summary:      summary,
+		Description:  task.Description,
+		LastModified: task.UpdatedAt,
+	}
+}
+
+// bookingNames 预约者的姓名，没有填写申请时使用昵称或邮箱，匿名评审轮次中的面试者使用化名
+func bookingNames(db *gorm.DB, blind *blindView, bookings []models.SlotBooking) []string {
+	userIds := make([]uuid.UUID, 0, len(bookings))
+	for _, booking := range bookings {
+		userIds = append(userIds, booking.UserID)
+	}
+	userNames := loadUserNames(db, userIds)
+	realNames := loadBookingRealNames(db, bookings)
+	for userUUID := range blind.maskNames(db, userNames) {
+		delete(realNames, userUUID.String())
+	}
+
+	names := make([]string, 0, len(bookings))
+	for _, booking := range bookings {
+		name := realNames[booking.UserID.String()]
+		if name == "" {
+			name = userNames[booking.UserID.String()]
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// buildUserCalendar 生成用户在当前招新季的日历
+// 面试官包含自己发布的时间段和布置的任务截止时间，面试者包含自己的预约和任务截止时间
+func buildUserCalendar(db *gorm.DB, user models.User) (*ical.Calendar, error) {
+	seasonID, err := currentSeasonID(db)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{Name: "XDSEC 招新面试"}
+
+	if user.Role == "interviewer" {
+		var slots []models.InterviewSlot
+		if err := db.Where("season_id = ? AND interviewer_id = ?", seasonID, user.UUID).Order("starts_at ASC").Find(&slots).Error; err != nil {
+			return nil, err
+		}
+		slotIds := make([]uuid.UUID, 0, len(slots))
+		for _, slot := range slots {
+			slotIds = append(slotIds, slot.UUID)
+		}
+		bookingsBySlot := make(map[uuid.UUID][]models.SlotBooking)
+		if len(slotIds) > 0 {
+			var bookings []models.SlotBooking
+			if err := db.Where("slot_id IN ? AND status = ?", slotIds, "booked").Order("created_at ASC").Find(&bookings).Error; err != nil {
+				return nil, err
+			}
+			for _, booking := range bookings {
+				bookingsBySlot[booking.SlotID] = append(bookingsBySlot[booking.SlotID], booking)
+			}
+		}
+
+		interviewerName := loadUserNames(db, []uuid.UUID{user.UUID})[user.UUID.String()]
+		blind := loadViewerBlindView(db, user.UUID, user.Role, seasonID)
+		for _, slot := range slots {
+			description := slotDescription(slot, interviewerName, true)
+			if bookings := bookingsBySlot[slot.UUID]; len(bookings) > 0 {
+				description += "\n已预约：" + strings.Join(bookingNames(db, blind, bookings), "、")
+			}
+			calendar.Events = append(calendar.Events, ical.Event{
+				UID:          fmt.Sprintf("slot-%s@%s", slot.UUID, calendarUIDDomain),
+				Sequence:     slot.Sequence,
+				Start:        slot.StartsAt,
+				End:          slot.StartsAt.Add(time.Duration(slot.Duration) * time.Minute),
+				Summary:      fmt.Sprintf("面试：%s（%d/%d）", slot.Direction, slot.BookedCount, slot.Capacity),
+				Description:  description,
+				Location:     slotLocation(slot, true),
+				URL:          slot.OnlineLink,
+				Cancelled:    slot.CancelledAt != nil,
+				LastModified: slot.UpdatedAt,
+			})
+		}
+
+		var tasks []models.Task
+		if err := db.Where("season_id = ? AND assigned_by = ? AND due_at IS NOT NULL", seasonID, user.UUID).Find(&tasks).Error; err != nil {
+			return nil, err
+		}
+		targetIds := make([]uuid.UUID, 0, len(tasks))
+		for _, task := range tasks {
+			targetIds = append(targetIds, task.TargetUserId)
+		}
+		targetNames := loadUserNames(db, targetIds)
+		blind.maskNames(db, targetNames)
+		for _, task := range tasks {
+			calendar.Events = append(calendar.Events, taskDeadlineEvent(task, fmt.Sprintf("任务截止：%s（%s）", task.Title, targetNames[task.TargetUserId.String()])))
+		}
+		return calendar, nil
+	}
+
+	var bookings []models.SlotBooking
+	if err := db.Where("season_id = ? AND user_id = ?", seasonID, user.UUID).Order("created_at ASC").Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	slotIds := make([]uuid.UUID, 0, len(bookings))
+	for _, booking := range bookings {
+		slotIds = append(slotIds, booking.SlotID)
+	}
+	slotsById := make(map[uuid.UUID]models.InterviewSlot)
+	interviewerIds := make([]uuid.UUID, 0, len(bookings))
+	if len(slotIds) > 0 {
+		var slots []models.InterviewSlot
+		if err := db.Where("uuid IN ?", slotIds).Find(&slots).Error; err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			slotsById[slot.UUID] = slot
+			interviewerIds = append(interviewerIds, slot.InterviewerID)
+		}
+	}
+	interviewerNames := loadUserNames(db, interviewerIds)
+	for _, booking := range bookings {
+		slot, exists := slotsById[booking.SlotID]
+		if !exists {
+			continue
+		}
+		calendar.Events = append(calendar.Events, bookingEvent(booking, slot, interviewerNames[slot.InterviewerID.String()]))
+	}
+
+	var tasks []models.Task
+	if err := db.Where("season_id = ? AND target_user_id = ? AND due_at IS NOT NULL", seasonID, user.UUID).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		calendar.Events = append(calendar.Events, taskDeadlineEvent(task, "任务截止："+task.Title))
+	}
+	return calendar, nil
+}
+
+// sendBookingConfirmation 发送预约确认邮件，附带该面试的 .ics 文件
+func sendBookingConfirmation(db *gorm.DB, booking models.SlotBooking, slot models.InterviewSlot) {
+	var user models.User
+	if err := db.Select("uuid", "email").Where("uuid = ?", booking.UserID).First(&user).Error; err != nil {
+		return
+	}
+	interviewerName := loadUserNames(db, []uuid.UUID{slot.InterviewerID})[slot.InterviewerID.String()]
+	event := bookingEvent(booking, slot, interviewerName)
+	calendar := ical.Calendar{Method: "PUBLISH", Events: []ical.Event{event}}
+
+	lines := []string{
+		fmt.Sprintf("你已成功预约 %s 方向的面试。", slot.Direction),
+		"",
+		fmt.Sprintf("时间：%s - %s", slot.StartsAt.Format("2006-01-02 15:04"), event.End.Format("15:04")),
+	}
+	if slot.Location != "" {
+		lines = append(lines, "地点："+slot.Location)
+	}
+	lines = append(lines,
+		slotDescription(slot, interviewerName, true),
+		"",
+		fmt.Sprintf("可以将附件导入日历应用。如需取消或改约，请在面试开始 %d 小时之前操作。", int(cancelCutoff.Hours())),
+	)
+	body := strings.Join(lines, "\n")
+	if err := smtp.SendWithCalendar(user.Email, "[XDSec Recruitment System] 面试预约成功", body, "interview.ics", calendar.Encode(time.Now())); err != nil {
+		log.Printf("发送预约确认邮件失败: %v", err)
+	}
+}
+
+// GetCalendarToken 获取自己的日历订阅链接，首次获取时生成
+func GetCalendarToken(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var token models.CalendarToken
+		err := db.Where("user_id = ?", userUUID).First(&token).Error
+		if err != nil {
+			value, err := auth.GenerateCalendarToken()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			token = models.CalendarToken{UserID: userUUID, Token: value}
+			if err := db.Create(&token).Error; err != nil {
+				// 并发请求已经生成过
+				if err := db.Where("user_id = ?", userUUID).First(&token).Error; err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+					return
+				}
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"path": calendarFeedPath(token.Token),
+			},
+		})
+	}
+}
+
+// ResetCalendarToken 重新生成日历订阅链接，原链接立即失效
+func ResetCalendarToken(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		value, err := auth.GenerateCalendarToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		token := models.CalendarToken{UserID: userUUID, Token: value, CreatedAt: time.Now()}
+		if err := db.Save(&token).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"path": calendarFeedPath(token.Token),
+			},
+		})
+	}
+}
+
+// GetCalendarFeed 日历订阅（公开，通过链接中的密钥识别用户）
+func GetCalendarFeed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, found := strings.CutSuffix(c.Param("file"), ".ics")
+		if !found || value == "" {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "日历不存在"})
+			return
+		}
+
+		var token models.CalendarToken
+		if err := db.Where("token = ?", value).First(&token).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "日历不存在"})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("uuid = ?", token.UserID).First(&user).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "日历不存在"})
+			return
+		}
+
+		calendar, err := buildUserCalendar(db, user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.Header("Content-Disposition", `inline; filename="xdsec-join.ics"`)
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar.Encode(time.Now()))
+	}
+}
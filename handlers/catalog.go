@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CatalogItemRequest 创建/更新学院或专业请求
+type CatalogItemRequest struct {
+	Name      string `json:"name" binding:"required,max=20"`
+	SortOrder int    `json:"sortOrder"`
+}
+
+// parseCatalogID 解析路径中的学院或专业ID
+func parseCatalogID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetDepartments 获取学院与专业目录（公开），目录为空时申请不校验学院与专业
+func GetDepartments(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var departments []models.Department
+		if err := db.Order("sort_order ASC, id ASC").Find(&departments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var majors []models.Major
+		if err := db.Order("sort_order ASC, id ASC").Find(&majors).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		majorsByDepartment := make(map[uint][]gin.H)
+		for _, major := range majors {
+			majorsByDepartment[major.DepartmentID] = append(majorsByDepartment[major.DepartmentID], gin.H{
+				"id":        major.ID,
+				"name":      template.HTMLEscapeString(major.Name),
+				"sortOrder": major.SortOrder,
+			})
+		}
+
+		items := make([]gin.H, 0, len(departments))
+		for _, department := range departments {
+			departmentMajors := majorsByDepartment[department.ID]
+			if departmentMajors == nil {
+				departmentMajors = []gin.H{}
+			}
+			items = append(items, gin.H{
+				"id":        department.ID,
+				"name":      template.HTMLEscapeString(department.Name),
+				"sortOrder": department.SortOrder,
+				"majors":    departmentMajors,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// CreateDepartment 新增学院（管理员）
+func CreateDepartment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CatalogItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+
+		var count int64
+		db.Model(&models.Department{}).Where("name = ?", req.Name).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "学院已存在"})
+			return
+		}
+
+		department := models.Department{Name: req.Name, SortOrder: req.SortOrder}
+		if err := db.Create(&department).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": department.ID,
+			},
+		})
+	}
+}
+
+// UpdateDepartment 修改学院（管理员），已提交的申请保留原名称
+func UpdateDepartment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseCatalogID(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req CatalogItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+
+		var department models.Department
+		if err := db.First(&department, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "学院不存在"})
+			return
+		}
+
+		var count int64
+		db.Model(&models.Department{}).Where("name = ? AND id <> ?", req.Name, id).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "学院已存在"})
+			return
+		}
+
+		updates := map[string]interface{}{
+			"name":       req.Name,
+			"sort_order": req.SortOrder,
+		}
+		if err := db.Model(&department).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteDepartment 删除学院及其下的专业（管理员）
+func DeleteDepartment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseCatalogID(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var department models.Department
+		if err := db.First(&department, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "学院不存在"})
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("department_id = ?", department.ID).Delete(&models.Major{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&department).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// CreateMajor 在学院下新增专业（管理员）
+func CreateMajor(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseCatalogID(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req CatalogItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+
+		var department models.Department
+		if err := db.First(&department, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "学院不存在"})
+			return
+		}
+
+		var count int64
+		db.Model(&models.Major{}).Where("department_id = ? AND name = ?", department.ID, req.Name).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "专业已存在"})
+			return
+		}
+
+		major := models.Major{DepartmentID: department.ID, Name: req.Name, SortOrder: req.SortOrder}
+		if err := db.Create(&major).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": major.ID,
+			},
+		})
+	}
+}
+
+// UpdateMajor 修改专业（管理员）
+func UpdateMajor(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseCatalogID(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req CatalogItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+
+		var major models.Major
+		if err := db.First(&major, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "专业不存在"})
+			return
+		}
+
+		var count int64
+		db.Model(&models.Major{}).Where("department_id = ? AND name = ? AND id <> ?", major.DepartmentID, req.Name, id).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "专业已存在"})
+			return
+		}
+
+		updates := map[string]interface{}{
+			"name":       req.Name,
+			"sort_order": req.SortOrder,
+		}
+		if err := db.Model(&major).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteMajor 删除专业（管理员）
+func DeleteMajor(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseCatalogID(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var major models.Major
+		if err := db.First(&major, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "专业不存在"})
+			return
+		}
+
+		if err := db.Delete(&major).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
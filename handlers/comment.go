@@ -58,6 +58,12 @@ func CreateComment(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// 检查利益冲突
+		if hasConflict(db, interviewerUUID, intervieweeUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": conflictMessage})
+			return
+		}
+
 		// 创建评论
 		commentUUID, _ := uuid.NewUUID()
 		comment := models.Comment{
@@ -197,6 +203,12 @@ func UpdateComment(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// 评论后才声明利益冲突的，不能再修改原有评论
+		if hasConflict(db, interviewerUUID, comment.IntervieweeID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": conflictMessage})
+			return
+		}
+
 		if err := db.Model(&comment).Update("content", req.Content).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// conflictMessage 面试官与面试者存在利益冲突时的提示
+const conflictMessage = "你已声明与该面试者存在利益冲突，不能进行此操作"
+
+// hasConflict 面试官是否声明了与面试者存在利益冲突
+func hasConflict(db *gorm.DB, interviewerUUID, userUUID uuid.UUID) bool {
+	var count int64
+	db.Model(&models.ConflictOfInterest{}).Where("interviewer_id = ? AND user_id = ?", interviewerUUID, userUUID).Count(&count)
+	return count > 0
+}
+
+// loadConflictPairs 查询所有利益冲突，键为 面试官ID/面试者ID
+func loadConflictPairs(db *gorm.DB) (map[string]bool, error) {
+	var conflicts []models.ConflictOfInterest
+	if err := db.Select("interviewer_id", "user_id").Find(&conflicts).Error; err != nil {
+		return nil, err
+	}
+	pairs := make(map[string]bool, len(conflicts))
+	for _, conflict := range conflicts {
+		pairs[conflict.InterviewerID.String()+"/"+conflict.UserID.String()] = true
+	}
+	return pairs, nil
+}
+
+// conflictItems 构建利益冲突列表的返回数据，匿名评审轮次中的面试者显示化名
+func conflictItems(db *gorm.DB, blind *blindView, conflicts []models.ConflictOfInterest) []gin.H {
+	userIds := make([]uuid.UUID, 0, len(conflicts)*2)
+	for _, conflict := range conflicts {
+		userIds = append(userIds, conflict.InterviewerID, conflict.UserID)
+	}
+	userNames := loadUserNames(db, userIds)
+	blind.maskNames(db, userNames)
+
+	items := make([]gin.H, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		items = append(items, gin.H{
+			"id":              conflict.UUID.String(),
+			"interviewerId":   conflict.InterviewerID.String(),
+			"interviewerName": template.HTMLEscapeString(userNames[conflict.InterviewerID.String()]),
+			"userId":          conflict.UserID.String(),
+			"userName":        template.HTMLEscapeString(userNames[conflict.UserID.String()]),
+			"reason":          template.HTMLEscapeString(conflict.Reason),
+			"createdAt":       conflict.CreatedAt,
+		})
+	}
+	return items
+}
+
+// GetConflicts 获取利益冲突声明（面试官）
+// 管理员可以看到所有声明，其他面试官只能看到自己的
+func GetConflicts(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		tx := db.Model(&models.ConflictOfInterest{})
+		if !IsAdmin(db, userUUID) {
+			tx = tx.Where("interviewer_id = ?", userUUID)
+		} else if interviewerID := c.Query("interviewerId"); interviewerID != "" {
+			interviewerUUID, err := uuid.Parse(interviewerID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+				return
+			}
+			tx = tx.Where("interviewer_id = ?", interviewerUUID)
+		}
+		if candidateID := c.Query("userId"); candidateID != "" {
+			candidateUUID, err := uuid.Parse(candidateID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+				return
+			}
+			tx = tx.Where("user_id = ?", candidateUUID)
+		}
+
+		var conflicts []models.ConflictOfInterest
+		if err := tx.Order("created_at DESC").Find(&conflicts).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var blind *blindView
+		if seasonID, err := currentSeasonID(db); err == nil {
+			blind = loadBlindView(db, c, seasonID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": conflictItems(db, blind, conflicts),
+			},
+		})
+	}
+}
+
+// DeclareConflictRequest 声明利益冲突请求
+type DeclareConflictRequest struct {
+	UserID string `json:"userId" binding:"required"`
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+// DeclareConflict 声明与面试者存在利益冲突（面试官），已有的分配一并取消
+func DeclareConflict(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DeclareConflictRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		interviewerUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		userUUID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("uuid = ? AND role = ?", userUUID, "interviewee").First(&user).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试者不存在"})
+			return
+		}
+
+		if hasConflict(db, interviewerUUID, userUUID) {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "已声明过利益冲突"})
+			return
+		}
+
+		conflictUUID, _ := uuid.NewUUID()
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&models.ConflictOfInterest{
+				UUID:          conflictUUID,
+				InterviewerID: interviewerUUID,
+				UserID:        userUUID,
+				Reason:        req.Reason,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Where("interviewer_id = ? AND user_id = ?", interviewerUUID, userUUID).Delete(&models.Assignment{}).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": conflictUUID.String(),
+			},
+		})
+	}
+}
+
+// DeleteConflict 撤回利益冲突声明（声明者或管理员）
+func DeleteConflict(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conflictUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var conflict models.ConflictOfInterest
+		if err := db.Where("uuid = ?", conflictUUID).First(&conflict).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "利益冲突声明不存在"})
+			return
+		}
+
+		if conflict.InterviewerID != userUUID && !IsAdmin(db, userUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "只能撤回自己的声明"})
+			return
+		}
+
+		if err := db.Delete(&conflict).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApplicationDraftRequest 保存申请草稿请求，所有字段均可缺省，只限制长度
+type ApplicationDraftRequest struct {
+	RealName   string   `json:"realName" binding:"max=10"`
+	Phone      string   `json:"phone" binding:"max=32"`
+	Gender     string   `json:"gender" binding:"max=10"`
+	Department string   `json:"department" binding:"max=20"`
+	Major      string   `json:"major" binding:"max=20"`
+	StudentId  string   `json:"studentId" binding:"max=20"`
+	Directions []string `json:"directions" binding:"max=30"`
+	Resume     string   `json:"resume" binding:"max=10000"`
+	// Answers 申请表自定义问题的回答
+	Answers []ApplicationAnswerItem `json:"answers" binding:"max=100,dive"`
+}
+
+// loadApplicationDraft 查询用户在当前招新季的草稿
+func loadApplicationDraft(db *gorm.DB, userUUID uuid.UUID) (*models.ApplicationDraft, error) {
+	seasonID, err := currentSeasonID(db)
+	if err != nil {
+		return nil, err
+	}
+	var draft models.ApplicationDraft
+	if err := db.Where("user_id = ? AND season_id = ?", userUUID, seasonID).First(&draft).Error; err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// parseDraftData 解析草稿内容
+func parseDraftData(draft models.ApplicationDraft) ApplicationDraftRequest {
+	var data ApplicationDraftRequest
+	json.Unmarshal([]byte(draft.Data), &data)
+	if data.Directions == nil {
+		data.Directions = []string{}
+	}
+	return data
+}
+
+// SaveApplicationDraft 保存申请草稿（自动保存），不做完整校验，面试官不可见
+func SaveApplicationDraft(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ApplicationDraftRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		dataJSON, _ := json.Marshal(req)
+
+		var draft models.ApplicationDraft
+		err = db.Where("user_id = ? AND season_id = ?", userUUID, seasonID).First(&draft).Error
+		if err == nil {
+			err = db.Model(&draft).Update("data", string(dataJSON)).Error
+		} else {
+			draft = models.ApplicationDraft{
+				UserID:   userUUID,
+				SeasonID: seasonID,
+				Data:     string(dataJSON),
+			}
+			err = db.Create(&draft).Error
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"savedAt": draft.UpdatedAt,
+			},
+		})
+	}
+}
+
+// GetApplicationDraft 获取自己的申请草稿
+func GetApplicationDraft(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		draft, err := loadApplicationDraft(db, userUUID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "没有保存的草稿"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"draft":   parseDraftData(*draft),
+				"savedAt": draft.UpdatedAt,
+			},
+		})
+	}
+}
+
+// DeleteApplicationDraft 丢弃自己的申请草稿
+func DeleteApplicationDraft(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		draft, err := loadApplicationDraft(db, userUUID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "没有保存的草稿"})
+			return
+		}
+
+		if err := db.Delete(draft).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// SubmitApplicationDraft 将草稿提交为正式申请，与直接提交申请执行相同的完整校验
+// 提交成功后删除草稿，校验失败时保留草稿
+func SubmitApplicationDraft(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		draft, err := loadApplicationDraft(db, userUUID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "没有保存的草稿"})
+			return
+		}
+
+		data := parseDraftData(*draft)
+		req := CreateApplicationRequest{
+			RealName:   data.RealName,
+			Phone:      data.Phone,
+			Gender:     data.Gender,
+			Department: data.Department,
+			Major:      data.Major,
+			StudentId:  data.StudentId,
+			Directions: data.Directions,
+			Resume:     data.Resume,
+			Answers:    data.Answers,
+		}
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			body := gin.H{"ok": false, "message": "草稿内容不完整，请补充后再提交"}
+			if errs := bindingErrors(err, &req); errs != nil {
+				body["errors"] = errs
+			}
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
+
+		code, body := saveApplication(db, userUUID, req)
+		if code != http.StatusOK {
+			c.JSON(code, body)
+			return
+		}
+
+		if err := db.Delete(draft).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "申请已提交，但删除草稿失败"})
+			return
+		}
+
+		c.JSON(code, body)
+	}
+}
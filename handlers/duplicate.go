@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// duplicateFields 用于检测重复申请的字段
+var duplicateFields = []string{"phone", "studentId", "realName"}
+
+// normalizePhoneKey 手机号只保留数字并去掉国家码
+func normalizePhoneKey(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	key := b.String()
+	if len(key) == 13 && strings.HasPrefix(key, "86") {
+		key = key[2:]
+	}
+	return key
+}
+
+// normalizeStudentIdKey 学号去掉空白并统一为大写
+func normalizeStudentIdKey(studentId string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(studentId), ""))
+}
+
+// normalizeNameKey 姓名去掉空白与间隔号并统一为小写
+func normalizeNameKey(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsSpace(r) || r == '·' || r == '•' || r == '.' {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// duplicateKeyColumns 申请用于重复检测的规范化字段
+func duplicateKeyColumns(realName, phone, studentId string) map[string]interface{} {
+	return map[string]interface{}{
+		"phone_key":      normalizePhoneKey(phone),
+		"student_id_key": normalizeStudentIdKey(studentId),
+		"name_key":       normalizeNameKey(realName),
+	}
+}
+
+// SeedDuplicateKeys 为旧申请补齐重复检测使用的规范化字段
+// 新申请总会写入这些字段（规范化后为空时写入空字符串），因此只处理 name_key 为 NULL 的旧数据，补齐后不会重复处理
+func SeedDuplicateKeys(db *gorm.DB) {
+	var applications []models.Application
+	err := db.Where("name_key IS NULL").FindInBatches(&applications, 200, func(tx *gorm.DB, batch int) error {
+		for _, application := range applications {
+			columns := duplicateKeyColumns(application.RealName, application.Phone, application.StudentId)
+			if err := db.Model(&models.Application{}).Where("id = ?", application.ID).UpdateColumns(columns).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		log.Printf("补齐重复检测字段失败: %v", err)
+	}
+}
+
+// dismissalPair 忽略记录中的用户对，较小的ID在前
+func dismissalPair(a, b uuid.UUID) [2]uuid.UUID {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return [2]uuid.UUID{a, b}
+}
+
+// loadDismissedPairs 查询涉及这些用户的已忽略用户对
+func loadDismissedPairs(db *gorm.DB, userIds []uuid.UUID) map[[2]uuid.UUID]struct{} {
+	result := make(map[[2]uuid.UUID]struct{})
+	if len(userIds) == 0 {
+		return result
+	}
+	var dismissals []models.DuplicateDismissal
+	if err := db.Where("user_a IN ? OR user_b IN ?", userIds, userIds).Find(&dismissals).Error; err != nil {
+		return result
+	}
+	for _, dismissal := range dismissals {
+		result[dismissalPair(dismissal.UserA, dismissal.UserB)] = struct{}{}
+	}
+	return result
+}
+
+// matchedFields 两份申请中相同的字段
+func matchedFields(a, b models.Application) []string {
+	fields := make([]string, 0, len(duplicateFields))
+	if a.PhoneKey != "" && a.PhoneKey == b.PhoneKey {
+		fields = append(fields, "phone")
+	}
+	if a.StudentIdKey != "" && a.StudentIdKey == b.StudentIdKey {
+		fields = append(fields, "studentId")
+	}
+	if a.NameKey != "" && a.NameKey == b.NameKey {
+		fields = append(fields, "realName")
+	}
+	return fields
+}
+
+// duplicateStrength 仅姓名相同时为 weak，手机号或学号相同时为 strong
+func duplicateStrength(fields []string) string {
+	if slices.Contains(fields, "phone") || slices.Contains(fields, "studentId") {
+		return "strong"
+	}
+	return "weak"
+}
+
+// findDuplicateApplications 查询同一招新季中与该申请疑似重复的其他用户的申请，已忽略的与需要隐藏身份的不返回
+func findDuplicateApplications(db *gorm.DB, blind *blindView, application models.Application) ([]gin.H, error) {
+	tx := db.Where("season_id = ? AND user_id <> ?", application.SeasonID, application.UserID)
+	conditions := db.Where("1 = 0")
+	if application.PhoneKey != "" {
+		conditions = conditions.Or("phone_key = ?", application.PhoneKey)
+	}
+	if application.StudentIdKey != "" {
+		conditions = conditions.Or("student_id_key = ?", application.StudentIdKey)
+	}
+	if application.NameKey != "" {
+		conditions = conditions.Or("name_key = ?", application.NameKey)
+	}
+
+	var candidates []models.Application
+	if err := tx.Where(conditions).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	userIds := make([]uuid.UUID, 0, len(candidates))
+	for _, candidate := range candidates {
+		userIds = append(userIds, candidate.UserID)
+	}
+	userNames := loadUserNames(db, userIds)
+	hidden := blind.maskNames(db, userNames)
+	dismissed := loadDismissedPairs(db, []uuid.UUID{application.UserID})
+
+	items := make([]gin.H, 0, len(candidates))
+	for _, candidate := range candidates {
+		if _, exists := dismissed[dismissalPair(application.UserID, candidate.UserID)]; exists || hidden[candidate.UserID] {
+			continue
+		}
+		fields := matchedFields(application, candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		items = append(items, gin.H{
+			"userId":    candidate.UserID.String(),
+			"name":      template.HTMLEscapeString(userNames[candidate.UserID.String()]),
+			"realName":  template.HTMLEscapeString(candidate.RealName),
+			"matchedOn": fields,
+			"strength":  duplicateStrength(fields),
+		})
+	}
+	return items, nil
+}
+
+// GetDuplicates 获取疑似重复申请的分组（面试官）
+// 手机号、学号或姓名相同的申请者连成一组，已忽略的用户对不参与分组
+func GetDuplicates(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		strength := c.Query("strength")
+		if strength != "" && strength != "strong" && strength != "weak" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "strength 参数校验失败"})
+			return
+		}
+
+		// 只加载至少有一个字段与其他用户相同的申请
+		duplicatedKeys := func(column string) *gorm.DB {
+			return db.Model(&models.Application{}).
+				Select(column).
+				Where("season_id = ? AND "+column+" <> ''", season.UUID).
+				Group(column).
+				Having("COUNT(DISTINCT user_id) > 1")
+		}
+		var applications []models.Application
+		err := db.Where("season_id = ?", season.UUID).
+			Where(db.Where("phone_key IN (?)", duplicatedKeys("phone_key")).
+				Or("student_id_key IN (?)", duplicatedKeys("student_id_key")).
+				Or("name_key IN (?)", duplicatedKeys("name_key"))).
+			Find(&applications).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		userIds := make([]uuid.UUID, 0, len(applications))
+		for _, application := range applications {
+			userIds = append(userIds, application.UserID)
+		}
+		dismissed := loadDismissedPairs(db, userIds)
+
+		// 按规范化字段分桶，只比较同一桶内的申请得出相同字段，并用并查集合并成组
+		parent := make(map[uuid.UUID]uuid.UUID)
+		var find func(id uuid.UUID) uuid.UUID
+		find = func(id uuid.UUID) uuid.UUID {
+			if parent[id] != id {
+				parent[id] = find(parent[id])
+			}
+			return parent[id]
+		}
+		buckets := make(map[string][]int)
+		bucketOrder := make([]string, 0)
+		for i, application := range applications {
+			parent[application.UserID] = application.UserID
+			for field, key := range map[string]string{"phone": application.PhoneKey, "studentId": application.StudentIdKey, "realName": application.NameKey} {
+				if key == "" {
+					continue
+				}
+				bucket := field + "/" + key
+				if _, exists := buckets[bucket]; !exists {
+					bucketOrder = append(bucketOrder, bucket)
+				}
+				buckets[bucket] = append(buckets[bucket], i)
+			}
+		}
+		slices.Sort(bucketOrder)
+
+		type pair struct {
+			a, b   models.Application
+			fields []string
+		}
+		pairs := make([]pair, 0)
+		compared := make(map[[2]uuid.UUID]struct{})
+		for _, bucket := range bucketOrder {
+			members := buckets[bucket]
+			for i := range members {
+				for j := i + 1; j < len(members); j++ {
+					a, b := applications[members[i]], applications[members[j]]
+					if a.UserID == b.UserID {
+						continue
+					}
+					key := dismissalPair(a.UserID, b.UserID)
+					if _, exists := compared[key]; exists {
+						continue
+					}
+					compared[key] = struct{}{}
+					if _, exists := dismissed[key]; exists {
+						continue
+					}
+					pairs = append(pairs, pair{a: a, b: b, fields: matchedFields(a, b)})
+					parent[find(a.UserID)] = find(b.UserID)
+				}
+			}
+		}
+
+		userNames := loadUserNames(db, userIds)
+		// 重复分组会暴露手机号与学号，包含匿名评审中面试者的分组不展示
+		hidden := loadSeasonBlindView(db, c, season.UUID).maskNames(db, userNames)
+		applicationByUser := make(map[uuid.UUID]models.Application)
+		for _, application := range applications {
+			applicationByUser[application.UserID] = application
+		}
+
+		type cluster struct {
+			members   []uuid.UUID
+			pairs     []gin.H
+			matchedOn []string
+		}
+		clusters := make(map[uuid.UUID]*cluster)
+		order := make([]uuid.UUID, 0)
+		for _, p := range pairs {
+			root := find(p.a.UserID)
+			group, exists := clusters[root]
+			if !exists {
+				group = &cluster{}
+				clusters[root] = group
+				order = append(order, root)
+			}
+			for _, member := range []uuid.UUID{p.a.UserID, p.b.UserID} {
+				if !slices.Contains(group.members, member) {
+					group.members = append(group.members, member)
+				}
+			}
+			for _, field := range p.fields {
+				if !slices.Contains(group.matchedOn, field) {
+					group.matchedOn = append(group.matchedOn, field)
+				}
+			}
+			group.pairs = append(group.pairs, gin.H{
+				"userA":     p.a.UserID.String(),
+				"userB":     p.b.UserID.String(),
+				"matchedOn": p.fields,
+			})
+		}
+
+		items := make([]gin.H, 0, len(order))
+		for _, root := range order {
+			group := clusters[root]
+			groupStrength := duplicateStrength(group.matchedOn)
+			if strength != "" && strength != groupStrength {
+				continue
+			}
+			if slices.ContainsFunc(group.members, func(member uuid.UUID) bool { return hidden[member] }) {
+				continue
+			}
+
+			members := make([]gin.H, 0, len(group.members))
+			for _, member := range group.members {
+				application := applicationByUser[member]
+				members = append(members, gin.H{
+					"userId":    member.String(),
+					"name":      template.HTMLEscapeString(userNames[member.String()]),
+					"realName":  template.HTMLEscapeString(application.RealName),
+					"phone":     template.HTMLEscapeString(application.Phone),
+					"studentId": template.HTMLEscapeString(application.StudentId),
+					"createdAt": application.CreatedAt,
+				})
+			}
+			sortedMatched := make([]string, 0, len(group.matchedOn))
+			for _, field := range duplicateFields {
+				if slices.Contains(group.matchedOn, field) {
+					sortedMatched = append(sortedMatched, field)
+				}
+			}
+			items = append(items, gin.H{
+				"members":   members,
+				"pairs":     group.pairs,
+				"matchedOn": sortedMatched,
+				"strength":  groupStrength,
+			})
+		}
+
+		// 手机号或学号相同的分组在前
+		slices.SortStableFunc(items, func(a, b gin.H) int {
+			if a["strength"] == b["strength"] {
+				return len(b["members"].([]gin.H)) - len(a["members"].([]gin.H))
+			}
+			if a["strength"] == "strong" {
+				return -1
+			}
+			return 1
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// DismissDuplicatesRequest 忽略疑似重复请求
+type DismissDuplicatesRequest struct {
+	UserIds []string `json:"userIds" binding:"required,min=2,max=20"`
+}
+
+// DismissDuplicates 将一组用户标记为不是同一人（管理员），之后不再互相提示重复
+func DismissDuplicates(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DismissDuplicatesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		adminUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		userIds := make([]uuid.UUID, 0, len(req.UserIds))
+		for _, id := range req.UserIds {
+			userUUID, err := uuid.Parse(id)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "用户ID格式错误"})
+				return
+			}
+			if !slices.Contains(userIds, userUUID) {
+				userIds = append(userIds, userUUID)
+			}
+		}
+		if len(userIds) < 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "至少需要两个不同的用户"})
+			return
+		}
+
+		var count int64
+		if err := db.Model(&models.User{}).Where("uuid IN ?", userIds).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if int(count) != len(userIds) {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "用户不存在"})
+			return
+		}
+
+		dismissals := make([]models.DuplicateDismissal, 0)
+		for i := range userIds {
+			for j := i + 1; j < len(userIds); j++ {
+				pair := dismissalPair(userIds[i], userIds[j])
+				dismissals = append(dismissals, models.DuplicateDismissal{
+					UserA:       pair[0],
+					UserB:       pair[1],
+					DismissedBy: adminUUID,
+				})
+			}
+		}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dismissals).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
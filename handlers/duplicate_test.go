@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"xdsec-join-2026/models"
+)
+
+func TestNormalizeDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		realName  string
+		phone     string
+		studentId string
+		want      map[string]interface{}
+	}{
+		{
+			name:      "plain",
+			realName:  "张三",
+			phone:     "13812345678",
+			studentId: "24009100001",
+			want:      map[string]interface{}{"phone_key": "13812345678", "student_id_key": "24009100001", "name_key": "张三"},
+		},
+		{
+			name:      "country code and separators",
+			realName:  " 张 三 ",
+			phone:     "+86 138-1234-5678",
+			studentId: " 24009 100001 ",
+			want:      map[string]interface{}{"phone_key": "13812345678", "student_id_key": "24009100001", "name_key": "张三"},
+		},
+		{
+			name:      "interpunct and case",
+			realName:  "Ayi·Mamat",
+			phone:     "86 13812345678",
+			studentId: "ab123",
+			want:      map[string]interface{}{"phone_key": "13812345678", "student_id_key": "AB123", "name_key": "ayimamat"},
+		},
+		{
+			name:      "short number keeps 86",
+			realName:  "",
+			phone:     "8612345",
+			studentId: "",
+			want:      map[string]interface{}{"phone_key": "8612345", "student_id_key": "", "name_key": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicateKeyColumns(tt.realName, tt.phone, tt.studentId); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("duplicateKeyColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchedFields(t *testing.T) {
+	base := models.Application{PhoneKey: "13812345678", StudentIdKey: "24009100001", NameKey: "张三"}
+	tests := []struct {
+		name     string
+		other    models.Application
+		want     []string
+		strength string
+	}{
+		{"all", base, []string{"phone", "studentId", "realName"}, "strong"},
+		{"name only", models.Application{NameKey: "张三"}, []string{"realName"}, "weak"},
+		{"student id", models.Application{StudentIdKey: "24009100001", NameKey: "李四"}, []string{"studentId"}, "strong"},
+		{"empty keys never match", models.Application{}, []string{}, "weak"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchedFields(base, tt.other)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchedFields() = %v, want %v", got, tt.want)
+			}
+			if strength := duplicateStrength(got); strength != tt.strength {
+				t.Errorf("duplicateStrength() = %q, want %q", strength, tt.strength)
+			}
+		})
+	}
+}
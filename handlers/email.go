@@ -214,7 +214,7 @@ func Register(db *gorm.DB) gin.HandlerFunc {
 			Nickname:           &req.Nickname,
 			Signature:          req.Signature,
 			Role:               "interviewee",
-			Status:             "r1_pending",
+			Status:             initialStatus(db),
 			Directions:         "[]",
 			PassedDirections:   "[]",
 			PassedDirectionsBy: "[]",
@@ -288,13 +288,14 @@ func Login(db *gorm.DB) gin.HandlerFunc {
 			"ok": true,
 			"data": gin.H{
 				"user": gin.H{
-					"id":         user.UUID.String(),
-					"role":       user.Role,
-					"nickname":   user.Nickname,
-					"email":      user.Email,
-					"signature":  user.Signature,
-					"directions": directions,
-					"status":     user.Status,
+					"id":          user.UUID.String(),
+					"role":        user.Role,
+					"nickname":    user.Nickname,
+					"email":       user.Email,
+					"signature":   user.Signature,
+					"directions":  directions,
+					"status":      user.Status,
+					"statusLabel": statusLabel(db, user.Status),
 				},
 				"csrfToken": csrfToken,
 			},
@@ -458,13 +459,14 @@ func GetCurrentUser(db *gorm.DB) gin.HandlerFunc {
 			"ok": true,
 			"data": gin.H{
 				"user": gin.H{
-					"id":         user.UUID.String(),
-					"role":       user.Role,
-					"nickname":   user.Nickname,
-					"email":      user.Email,
-					"signature":  user.Signature,
-					"directions": directions,
-					"status":     user.Status,
+					"id":          user.UUID.String(),
+					"role":        user.Role,
+					"nickname":    user.Nickname,
+					"email":       user.Email,
+					"signature":   user.Signature,
+					"directions":  directions,
+					"status":      user.Status,
+					"statusLabel": statusLabel(db, user.Status),
 				},
 			},
 		})
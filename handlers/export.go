@@ -3,6 +3,8 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 	"xdsec-join-2026/models"
 
 	"github.com/gin-gonic/gin"
@@ -14,12 +16,59 @@ import (
 // ExportApplications 导出申请信息为Excel（面试官）
 func ExportApplications(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取所有面试者和他们的申请信息
+		// 获取过滤条件（查询参数或保存视图），未指定时导出全部面试者
+		filter, code, message := resolveUserFilter(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		// 指定 season 时只导出该招新季的申请者，否则导出全部面试者及其当前招新季的申请
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		// 获取面试者和他们的申请信息
 		var users []models.User
-		if err := db.Where("role = ?", "interviewee").Preload("Application").Find(&users).Error; err != nil {
+		tx := db.Where("role = ?", "interviewee").Preload("Application", "season_id = ?", season.UUID)
+		if c.Query("season") != "" {
+			tx = tx.Where("uuid IN (?)", db.Model(&models.Application{}).Select("user_id").Where("season_id = ?", season.UUID))
+		}
+		// 匿名评审轮次中的面试者只导出化名，只作用于当前招新季
+		var blind *blindView
+		if currentID, err := currentSeasonID(db); err == nil && currentID == season.UUID {
+			blind = loadBlindView(db, c, season.UUID)
+		}
+		tx = filter.Apply(db, tx, true, blind)
+		if err := tx.Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		// 批量查询标签
+		userIds := make([]uuid.UUID, 0, len(users))
+		for _, user := range users {
+			userIds = append(userIds, user.UUID)
+		}
+		userTagNames := loadUserTagNames(db, userIds)
+		labels := statusLabels(db)
+		seasonStatuses := loadSeasonStatuses(db, userIds, *season)
+
+		// 招新季的自定义问题及回答，每个问题一列
+		questions, err := loadFormQuestions(db, season.UUID)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
+		applicationIds := make([]uint, 0, len(users))
+		for _, user := range users {
+			if user.Application != nil {
+				applicationIds = append(applicationIds, user.Application.ID)
+			}
+		}
+		answers := loadAnswersByApplication(db, applicationIds)
 
 		// 创建Excel文件
 		file := xlsx.NewFile()
@@ -32,8 +81,11 @@ func ExportApplications(db *gorm.DB) gin.HandlerFunc {
 		// 设置表头
 		headers := []string{
 			"用户ID", "邮箱", "昵称", "签名", "面试状态",
-			"真实姓名", "手机号", "性别", "学院", "专业", "学号",
-			"申请方向", "简历", "通过方向", "通过面试官", "创建时间", "更新时间",
+			"真实姓名", "手机号", "性别", "学院", "专业", "学号", "入学年份",
+			"申请方向", "简历", "通过方向", "通过面试官", "标签", "创建时间", "更新时间",
+		}
+		for _, question := range questions {
+			headers = append(headers, question.Title)
 		}
 		headerRow := sheet.AddRow()
 		for _, header := range headers {
@@ -43,21 +95,40 @@ func ExportApplications(db *gorm.DB) gin.HandlerFunc {
 		// 填充数据
 		for _, user := range users {
 			row := sheet.AddRow()
+			masked := blind.masked(user)
 
 			// 用户基本信息
 			row.AddCell().Value = user.UUID.String()
-			row.AddCell().Value = user.Email
-			if user.Nickname != nil {
-				row.AddCell().Value = *user.Nickname
-			} else {
+			if masked {
 				row.AddCell().Value = ""
+				row.AddCell().Value = pseudonym(season.UUID, user.UUID)
+			} else {
+				row.AddCell().Value = user.Email
+				if user.Nickname != nil {
+					row.AddCell().Value = *user.Nickname
+				} else {
+					row.AddCell().Value = ""
+				}
 			}
 			row.AddCell().Value = user.Signature
-			row.AddCell().Value = user.Status
+			// 面试状态使用配置的展示名称，往届招新季使用当季的状态
+			status := user.Status
+			if seasonStatus, exists := seasonStatuses[user.UUID]; exists {
+				status = seasonStatus
+			}
+			if label, exists := labels[status]; exists {
+				row.AddCell().Value = label
+			} else {
+				row.AddCell().Value = status
+			}
 
 			// 申请信息
 			if user.Application != nil {
 				app := user.Application
+				if masked {
+					maskedApplication := maskApplication(*app)
+					app = &maskedApplication
+				}
 
 				row.AddCell().Value = app.RealName
 				row.AddCell().Value = app.Phone
@@ -65,6 +136,11 @@ func ExportApplications(db *gorm.DB) gin.HandlerFunc {
 				row.AddCell().Value = app.Department
 				row.AddCell().Value = app.Major
 				row.AddCell().Value = app.StudentId
+				if app.EnrollmentYear > 0 {
+					row.AddCell().Value = strconv.Itoa(app.EnrollmentYear)
+				} else {
+					row.AddCell().Value = ""
+				}
 
 				// 解析申请方向
 				if app.Directions != "" {
@@ -85,7 +161,7 @@ func ExportApplications(db *gorm.DB) gin.HandlerFunc {
 				row.AddCell().Value = app.Resume
 			} else {
 				// 无申请信息时填充空值
-				for i := 0; i < 8; i++ {
+				for i := 0; i < 9; i++ {
 					row.AddCell().Value = ""
 				}
 			}
@@ -122,8 +198,26 @@ func ExportApplications(db *gorm.DB) gin.HandlerFunc {
 				row.AddCell().Value = ""
 			}
 
+			// 标签
+			row.AddCell().Value = strings.Join(userTagNames[user.UUID], ", ")
+
 			row.AddCell().Value = user.CreatedAt.Format("2006-01-02 15:04:05")
 			row.AddCell().Value = user.UpdatedAt.Format("2006-01-02 15:04:05")
+
+			// 自定义问题的回答
+			for _, question := range questions {
+				value := ""
+				if user.Application != nil {
+					value = answerText(question, answers[user.Application.ID][question.UUID])
+				}
+				row.AddCell().Value = value
+			}
+		}
+
+		// 评分排名：当前导出的面试者在该招新季各方向各轮次的排名
+		if err := addRankingSheet(db, file, *season, users, blind); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "创建Excel文件失败"})
+			return
 		}
 
 		// 生成文件名
@@ -142,3 +236,87 @@ func ExportApplications(db *gorm.DB) gin.HandlerFunc {
 		c.Status(http.StatusOK)
 	}
 }
+
+// addRankingSheet 添加评分排名工作表，排名在该评分标准的全部面试者中计算
+func addRankingSheet(db *gorm.DB, file *xlsx.File, season models.Season, users []models.User, blind *blindView) error {
+	sheet, err := file.AddSheet("评分排名")
+	if err != nil {
+		return err
+	}
+
+	headers := []string{"方向", "轮次", "排名", "用户ID", "昵称", "评分数", "原始均分", "标准化均分", "标准差", "分差", "需要讨论"}
+	headerRow := sheet.AddRow()
+	for _, header := range headers {
+		headerRow.AddCell().Value = header
+	}
+
+	exported := make(map[uuid.UUID]models.User, len(users))
+	for _, user := range users {
+		exported[user.UUID] = user
+	}
+
+	var rubrics []models.Rubric
+	if err := db.Where("season_id = ?", season.UUID).Order("round ASC, direction ASC").Find(&rubrics).Error; err != nil {
+		return err
+	}
+	for _, rubric := range rubrics {
+		result, err := buildRanking(db, rubric)
+		if err != nil {
+			return err
+		}
+		for _, entry := range result.Entries {
+			user, exists := exported[entry.UserID]
+			if !exists {
+				continue
+			}
+			nickname := ""
+			if user.Nickname != nil {
+				nickname = *user.Nickname
+			}
+			if blind.masked(user) {
+				nickname = pseudonym(season.UUID, user.UUID)
+			}
+			divergent := ""
+			if entry.Divergent {
+				divergent = "是"
+			}
+
+			row := sheet.AddRow()
+			row.AddCell().Value = rubric.Direction
+			row.AddCell().SetInt(rubric.Round)
+			row.AddCell().SetInt(entry.Rank)
+			row.AddCell().Value = user.UUID.String()
+			row.AddCell().Value = nickname
+			row.AddCell().SetInt(len(entry.Scores))
+			row.AddCell().SetFloat(round2(entry.RawAverage))
+			row.AddCell().SetFloat(round2(entry.ZAverage))
+			row.AddCell().SetFloat(round2(entry.Spread))
+			row.AddCell().SetFloat(round2(entry.Range))
+			row.AddCell().Value = divergent
+		}
+	}
+	return nil
+}
+
+// loadSeasonStatuses 汇总往届招新季中各面试者的状态，当前招新季直接使用 User.Status
+func loadSeasonStatuses(db *gorm.DB, userIds []uuid.UUID, season models.Season) map[uuid.UUID]string {
+	result := make(map[uuid.UUID]string)
+	if season.Active || len(userIds) == 0 {
+		return result
+	}
+
+	var directionStatuses []models.DirectionStatus
+	if err := db.Where("user_id IN ? AND season_id = ?", userIds, season.UUID).Find(&directionStatuses).Error; err != nil {
+		return result
+	}
+
+	statusesMap := make(map[uuid.UUID][]string)
+	for _, directionStatus := range directionStatuses {
+		statusesMap[directionStatus.UserID] = append(statusesMap[directionStatus.UserID], directionStatus.Status)
+	}
+	ranks := statusRanks(db)
+	for userID, statuses := range statusesMap {
+		result[userID] = aggregateStatus(statuses, ranks)
+	}
+	return result
+}
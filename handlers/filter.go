@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserFilter 用户列表过滤条件，同时作为保存视图的过滤定义
+type UserFilter struct {
+	Role       string   `json:"role,omitempty"`
+	Query      string   `json:"q,omitempty"`
+	Statuses   []string `json:"statuses,omitempty"`
+	Directions []string `json:"directions,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	HasReport  *bool    `json:"hasReport,omitempty"`
+}
+
+// parseUserFilter 从查询参数解析过滤条件
+func parseUserFilter(db *gorm.DB, c *gin.Context) (UserFilter, error) {
+	filter := UserFilter{
+		Role:       c.Query("role"),
+		Query:      c.Query("q"),
+		Statuses:   splitQueryList(c.QueryArray("status")),
+		Directions: splitQueryList(c.QueryArray("direction")),
+		Tags:       splitQueryList(c.QueryArray("tag")),
+	}
+
+	if raw := c.Query("hasReport"); raw != "" {
+		hasReport, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("invalid hasReport")
+		}
+		filter.HasReport = &hasReport
+	}
+
+	return filter, filter.Validate(db)
+}
+
+// Validate 校验过滤条件
+func (f UserFilter) Validate(db *gorm.DB) error {
+	if f.Role != "" && !auth.ValidateRole(f.Role) {
+		return errors.New("invalid role")
+	}
+	for _, status := range f.Statuses {
+		if !validateStatus(db, status) {
+			return fmt.Errorf("invalid status: %s", status)
+		}
+	}
+	for _, direction := range f.Directions {
+		if !auth.ValidateDirection(direction) {
+			return fmt.Errorf("invalid direction: %s", direction)
+		}
+	}
+	if _, err := parseTagFilter(f.Tags); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Apply 将过滤条件应用到用户查询上
+// 标签与报告提交情况仅面试官可用，避免面试者借此推断内部信息
+// blind 不为 nil 时关键词不匹配需要隐藏身份的面试者，避免借搜索将化名与真实身份对应
+func (f UserFilter) Apply(db *gorm.DB, tx *gorm.DB, interviewer bool, blind *blindView) *gorm.DB {
+	// 按角色过滤
+	if f.Role != "" {
+		tx = tx.Where("role = ?", f.Role)
+	}
+
+	// 按关键词搜索（昵称或邮箱）
+	if f.Query != "" {
+		keyword := db.Where("nickname LIKE ? OR email LIKE ?", "%"+f.Query+"%", "%"+f.Query+"%")
+		if unmasked := blind.unmaskedScope(db); unmasked != nil {
+			keyword = keyword.Where(unmasked)
+		}
+		tx = tx.Where(keyword)
+	}
+
+	// 按面试状态过滤
+	if len(f.Statuses) > 0 {
+		tx = tx.Where("status IN ?", f.Statuses)
+	}
+
+	// 按申请方向过滤（多个方向之间为“或”关系）
+	if len(f.Directions) > 0 {
+		directionQuery := db.Where("JSON_CONTAINS(directions, ?)", fmt.Sprintf("\"%s\"", f.Directions[0]))
+		for _, direction := range f.Directions[1:] {
+			directionQuery = directionQuery.Or("JSON_CONTAINS(directions, ?)", fmt.Sprintf("\"%s\"", direction))
+		}
+		tx = tx.Where(directionQuery)
+	}
+
+	if !interviewer {
+		return tx
+	}
+
+	// 按标签过滤（多个标签之间为“或”关系）
+	if tagIds, _ := parseTagFilter(f.Tags); len(tagIds) > 0 {
+		tx = tx.Where("uuid IN (?)", db.Model(&models.UserTag{}).Select("user_id").Where("tag_id IN ?", tagIds))
+	}
+
+	// 按是否提交过任务报告过滤
+	if f.HasReport != nil {
+		reported := db.Model(&models.Task{}).Select("target_user_id").Where("report <> ''")
+		if *f.HasReport {
+			tx = tx.Where("uuid IN (?)", reported)
+		} else {
+			tx = tx.Where("uuid NOT IN (?)", reported)
+		}
+	}
+
+	return tx
+}
+
+// resolveUserFilter 解析请求中的过滤条件：指定 view 时使用保存视图的条件，否则使用查询参数
+func resolveUserFilter(db *gorm.DB, c *gin.Context) (UserFilter, int, string) {
+	viewID := c.Query("view")
+	if viewID == "" {
+		filter, err := parseUserFilter(db, c)
+		if err != nil {
+			return filter, http.StatusBadRequest, "参数校验失败"
+		}
+		return filter, http.StatusOK, ""
+	}
+
+	// 保存视图仅面试官可用
+	if GetCurrentUserRole(c) != "interviewer" {
+		return UserFilter{}, http.StatusForbidden, "无权限"
+	}
+
+	viewUUID, err := uuid.Parse(viewID)
+	if err != nil {
+		return UserFilter{}, http.StatusBadRequest, "参数校验失败"
+	}
+
+	userUUID, ok := GetCurrentUserUUID(c)
+	if !ok {
+		return UserFilter{}, http.StatusUnauthorized, "未登录"
+	}
+
+	view, err := loadAccessibleView(db, viewUUID, userUUID)
+	if err != nil {
+		return UserFilter{}, http.StatusNotFound, "视图不存在"
+	}
+
+	filter, err := savedViewFilter(*view)
+	if err != nil {
+		return filter, http.StatusInternalServerError, "视图过滤条件已损坏"
+	}
+	return filter, http.StatusOK, ""
+}
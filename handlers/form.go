@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// formQuestionTypes 自定义问题支持的类型
+var formQuestionTypes = []string{"text", "textarea", "single", "multiple", "url", "number"}
+
+// maxAnswerLength 未设置长度限制时回答的最大长度
+const maxAnswerLength = 10000
+
+// ApplicationAnswerItem 申请中对自定义问题的回答
+// 多选题使用 values，其余类型使用 value
+type ApplicationAnswerItem struct {
+	QuestionID string   `json:"questionId" binding:"required"`
+	Value      string   `json:"value" binding:"max=10000"`
+	Values     []string `json:"values" binding:"max=50"`
+}
+
+// loadFormQuestions 按顺序查询招新季的自定义问题
+func loadFormQuestions(db *gorm.DB, seasonUUID uuid.UUID) ([]models.FormQuestion, error) {
+	var questions []models.FormQuestion
+	if err := db.Where("season_id = ?", seasonUUID).Order("sort_order ASC, created_at ASC").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// questionApplies 检查问题是否对申请了这些方向的面试者可见，未限定方向的问题对所有人可见
+func questionApplies(question models.FormQuestion, directions []string) bool {
+	questionDirections := parseJSONList(question.Directions)
+	if len(questionDirections) == 0 {
+		return true
+	}
+	for _, direction := range directions {
+		if slices.Contains(questionDirections, direction) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAnswers 按问题定义校验回答，返回要保存的回答与每个问题的错误信息
+func validateAnswers(questions []models.FormQuestion, directions []string, answers []ApplicationAnswerItem) (map[uuid.UUID]string, map[string]string) {
+	values := make(map[uuid.UUID]string)
+	errs := make(map[string]string)
+
+	questionMap := make(map[string]models.FormQuestion)
+	for _, question := range questions {
+		questionMap[question.UUID.String()] = question
+	}
+
+	for _, answer := range answers {
+		question, exists := questionMap[answer.QuestionID]
+		if !exists || !questionApplies(question, directions) {
+			errs[answer.QuestionID] = "问题不存在"
+			continue
+		}
+
+		options := parseJSONList(question.Options)
+		switch question.Type {
+		case "multiple":
+			if slices.ContainsFunc(answer.Values, func(value string) bool { return !slices.Contains(options, value) }) {
+				errs[answer.QuestionID] = "选项不存在"
+				continue
+			}
+			selected := make([]string, 0, len(answer.Values))
+			for _, value := range answer.Values {
+				if !slices.Contains(selected, value) {
+					selected = append(selected, value)
+				}
+			}
+			if len(selected) > 0 {
+				selectedJSON, _ := json.Marshal(selected)
+				values[question.UUID] = string(selectedJSON)
+			}
+			continue
+		case "single":
+			if answer.Value != "" && !slices.Contains(options, answer.Value) {
+				errs[answer.QuestionID] = "选项不存在"
+				continue
+			}
+		case "url":
+			if answer.Value != "" {
+				parsed, err := url.Parse(answer.Value)
+				if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+					errs[answer.QuestionID] = "请输入以 http:// 或 https:// 开头的链接"
+					continue
+				}
+			}
+		case "number":
+			if answer.Value != "" {
+				if _, err := strconv.ParseFloat(answer.Value, 64); err != nil {
+					errs[answer.QuestionID] = "请输入数字"
+					continue
+				}
+			}
+		}
+
+		maxLength := question.MaxLength
+		if maxLength <= 0 {
+			maxLength = maxAnswerLength
+		}
+		if utf8.RuneCountInString(answer.Value) > maxLength {
+			errs[answer.QuestionID] = "回答不能超过 " + strconv.Itoa(maxLength) + " 字"
+			continue
+		}
+		if strings.TrimSpace(answer.Value) != "" {
+			values[question.UUID] = answer.Value
+		}
+	}
+
+	// 检查必答题
+	for _, question := range questions {
+		if !question.Required || !questionApplies(question, directions) {
+			continue
+		}
+		if _, answered := values[question.UUID]; !answered {
+			if _, failed := errs[question.UUID.String()]; !failed {
+				errs[question.UUID.String()] = "此题为必答题"
+			}
+		}
+	}
+
+	return values, errs
+}
+
+// saveApplicationAnswers 整体替换申请的回答
+func saveApplicationAnswers(tx *gorm.DB, applicationID uint, values map[uuid.UUID]string) error {
+	if err := tx.Where("application_id = ?", applicationID).Delete(&models.ApplicationAnswer{}).Error; err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	answers := make([]models.ApplicationAnswer, 0, len(values))
+	for questionID, value := range values {
+		answers = append(answers, models.ApplicationAnswer{
+			ApplicationID: applicationID,
+			QuestionID:    questionID,
+			Value:         value,
+		})
+	}
+	return tx.Create(&answers).Error
+}
+
+// loadApplicationAnswers 查询申请的回答
+func loadApplicationAnswers(db *gorm.DB, applicationID uint) map[uuid.UUID]string {
+	result := make(map[uuid.UUID]string)
+	var answers []models.ApplicationAnswer
+	if err := db.Where("application_id = ?", applicationID).Find(&answers).Error; err != nil {
+		return result
+	}
+	for _, answer := range answers {
+		result[answer.QuestionID] = answer.Value
+	}
+	return result
+}
+
+// loadAnswersByApplication 批量查询多个申请的回答
+func loadAnswersByApplication(db *gorm.DB, applicationIds []uint) map[uint]map[uuid.UUID]string {
+	result := make(map[uint]map[uuid.UUID]string)
+	if len(applicationIds) == 0 {
+		return result
+	}
+	var answers []models.ApplicationAnswer
+	if err := db.Where("application_id IN ?", applicationIds).Find(&answers).Error; err != nil {
+		return result
+	}
+	for _, answer := range answers {
+		if result[answer.ApplicationID] == nil {
+			result[answer.ApplicationID] = make(map[uuid.UUID]string)
+		}
+		result[answer.ApplicationID][answer.QuestionID] = answer.Value
+	}
+	return result
+}
+
+// answerText 回答的展示文本，多选题以逗号分隔
+func answerText(question models.FormQuestion, value string) string {
+	if question.Type == "multiple" {
+		return strings.Join(parseJSONList(value), ", ")
+	}
+	return value
+}
+
+// answerData 按问题顺序构建回答的返回数据
+func answerData(questions []models.FormQuestion, values map[uuid.UUID]string) []gin.H {
+	items := make([]gin.H, 0, len(values))
+	for _, question := range questions {
+		value, exists := values[question.UUID]
+		if !exists {
+			continue
+		}
+		item := gin.H{
+			"questionId": question.UUID.String(),
+			"title":      template.HTMLEscapeString(question.Title),
+			"type":       question.Type,
+		}
+		if question.Type == "multiple" {
+			selected := parseJSONList(value)
+			for i := range selected {
+				selected[i] = template.HTMLEscapeString(selected[i])
+			}
+			item["values"] = selected
+		} else {
+			item["value"] = template.HTMLEscapeString(value)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// formQuestionData 构建问题定义的返回数据
+func formQuestionData(question models.FormQuestion) gin.H {
+	options := parseJSONList(question.Options)
+	for i := range options {
+		options[i] = template.HTMLEscapeString(options[i])
+	}
+	return gin.H{
+		"id":          question.UUID.String(),
+		"title":       template.HTMLEscapeString(question.Title),
+		"description": template.HTMLEscapeString(question.Description),
+		"type":        question.Type,
+		"required":    question.Required,
+		"maxLength":   question.MaxLength,
+		"options":     options,
+		"directions":  parseJSONList(question.Directions),
+		"sortOrder":   question.SortOrder,
+	}
+}
+
+// GetFormQuestions 获取申请表的自定义问题
+func GetFormQuestions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		questions, err := loadFormQuestions(db, season.UUID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		// 指定方向时只返回对这些方向可见的问题
+		directions := splitQueryList(c.QueryArray("direction"))
+		items := make([]gin.H, 0, len(questions))
+		for _, question := range questions {
+			if len(directions) > 0 && !questionApplies(question, directions) {
+				continue
+			}
+			items = append(items, formQuestionData(question))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// FormQuestionRequest 创建/更新自定义问题请求
+type FormQuestionRequest struct {
+	Title       string   `json:"title" binding:"required,max=100"`
+	Description string   `json:"description" binding:"max=500"`
+	Type        string   `json:"type" binding:"required"`
+	Required    bool     `json:"required"`
+	MaxLength   int      `json:"maxLength" binding:"min=0,max=10000"`
+	Options     []string `json:"options" binding:"max=50,dive,max=100"`
+	Directions  []string `json:"directions"`
+	SortOrder   int      `json:"sortOrder"`
+}
+
+// validate 校验问题定义
+func (r FormQuestionRequest) validate() string {
+	if !slices.Contains(formQuestionTypes, r.Type) {
+		return "问题类型不合法"
+	}
+	if len(r.Directions) > 0 && !auth.ValidateDirections(r.Directions) {
+		return "方向参数校验失败"
+	}
+	if r.Type == "single" || r.Type == "multiple" {
+		if len(r.Options) == 0 {
+			return "选择题至少需要一个选项"
+		}
+		seen := make(map[string]struct{})
+		for _, option := range r.Options {
+			if option == "" {
+				return "选项不能为空"
+			}
+			if _, exists := seen[option]; exists {
+				return "选项不能重复"
+			}
+			seen[option] = struct{}{}
+		}
+	}
+	return ""
+}
+
+// columns 问题定义对应的数据库字段
+func (r FormQuestionRequest) columns() map[string]interface{} {
+	options := r.Options
+	if r.Type != "single" && r.Type != "multiple" {
+		options = []string{}
+	}
+	directions := r.Directions
+	if directions == nil {
+		directions = []string{}
+	}
+	optionsJSON, _ := json.Marshal(options)
+	directionsJSON, _ := json.Marshal(directions)
+	return map[string]interface{}{
+		"title":       r.Title,
+		"description": r.Description,
+		"type":        r.Type,
+		"required":    r.Required,
+		"max_length":  r.MaxLength,
+		"options":     string(optionsJSON),
+		"directions":  string(directionsJSON),
+		"sort_order":  r.SortOrder,
+	}
+}
+
+// CreateFormQuestion 在当前招新季的申请表中新增问题（管理员）
+func CreateFormQuestion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req FormQuestionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if message := req.validate(); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		columns := req.columns()
+		questionUUID, _ := uuid.NewUUID()
+		question := models.FormQuestion{
+			UUID:        questionUUID,
+			SeasonID:    seasonID,
+			Title:       req.Title,
+			Description: req.Description,
+			Type:        req.Type,
+			Required:    req.Required,
+			MaxLength:   req.MaxLength,
+			Options:     columns["options"].(string),
+			Directions:  columns["directions"].(string),
+			SortOrder:   req.SortOrder,
+		}
+		if err := db.Create(&question).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": questionUUID.String(),
+			},
+		})
+	}
+}
+
+// UpdateFormQuestion 修改申请表中的问题（管理员），已有的回答不受影响
+func UpdateFormQuestion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		questionUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req FormQuestionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if message := req.validate(); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var question models.FormQuestion
+		if err := db.Where("uuid = ?", questionUUID).First(&question).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "问题不存在"})
+			return
+		}
+
+		if err := db.Model(&question).Updates(req.columns()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteFormQuestion 删除申请表中的问题及其所有回答（管理员）
+func DeleteFormQuestion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		questionUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var question models.FormQuestion
+		if err := db.Where("uuid = ?", questionUUID).First(&question).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "问题不存在"})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("question_id = ?", questionUUID).Delete(&models.ApplicationAnswer{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&question).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
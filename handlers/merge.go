@@ -0,0 +1,553 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"slices"
+	"time"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// mergeReference 引用用户的字段，合并账户时直接改为保留的账户
+type mergeReference struct {
+	Name   string
+	Model  interface{}
+	Column string
+}
+
+// mergeReferences 没有唯一约束冲突、可以直接改写的用户引用
+// 新增引用用户的数据表时需要同步加入，否则合并后的数据会指向已删除的账户
+var mergeReferences = []mergeReference{
+	{Name: "tasks.target_user_id", Model: &models.Task{}, Column: "target_user_id"},
+	{Name: "tasks.assigned_by", Model: &models.Task{}, Column: "assigned_by"},
+	{Name: "tasks.reviewed_by", Model: &models.Task{}, Column: "reviewed_by"},
+	{Name: "tasks.extended_by", Model: &models.Task{}, Column: "extended_by"},
+	{Name: "comments.interviewee_id", Model: &models.Comment{}, Column: "interviewee_id"},
+	{Name: "comments.interviewer_id", Model: &models.Comment{}, Column: "interviewer_id"},
+	{Name: "announcements.author_id", Model: &models.Announcement{}, Column: "author_id"},
+	{Name: "tags.created_by", Model: &models.Tag{}, Column: "created_by"},
+	{Name: "user_tags.tagged_by", Model: &models.UserTag{}, Column: "tagged_by"},
+	{Name: "tag_logs.user_id", Model: &models.TagLog{}, Column: "user_id"},
+	{Name: "tag_logs.actor_id", Model: &models.TagLog{}, Column: "actor_id"},
+	{Name: "saved_views.owner_id", Model: &models.SavedView{}, Column: "owner_id"},
+	{Name: "status_changes.user_id", Model: &models.StatusChange{}, Column: "user_id"},
+	{Name: "status_changes.actor_id", Model: &models.StatusChange{}, Column: "actor_id"},
+	{Name: "direction_statuses.updated_by", Model: &models.DirectionStatus{}, Column: "updated_by"},
+	{Name: "application_extensions.granted_by", Model: &models.ApplicationExtension{}, Column: "granted_by"},
+	{Name: "application_revisions.user_id", Model: &models.ApplicationRevision{}, Column: "user_id"},
+	{Name: "application_revisions.author_id", Model: &models.ApplicationRevision{}, Column: "author_id"},
+	{Name: "attachments.owner_id", Model: &models.Attachment{}, Column: "owner_id"},
+	{Name: "duplicate_dismissals.dismissed_by", Model: &models.DuplicateDismissal{}, Column: "dismissed_by"},
+	{Name: "merge_records.merged_by", Model: &models.MergeRecord{}, Column: "merged_by"},
+	{Name: "interview_slots.interviewer_id", Model: &models.InterviewSlot{}, Column: "interviewer_id"},
+	{Name: "assignments.interviewer_id", Model: &models.Assignment{}, Column: "interviewer_id"},
+	{Name: "assignments.assigned_by", Model: &models.Assignment{}, Column: "assigned_by"},
+	{Name: "blind_reveals.revealed_by", Model: &models.BlindReveal{}, Column: "revealed_by"},
+	{Name: "task_report_versions.user_id", Model: &models.TaskReportVersion{}, Column: "user_id"},
+	{Name: "task_report_versions.reviewed_by", Model: &models.TaskReportVersion{}, Column: "reviewed_by"},
+}
+
+// mergeDetails 合并审计记录中的详细信息
+type mergeDetails struct {
+	// Counts 各数据表改为指向保留账户的记录数
+	Counts map[string]int64 `json:"counts"`
+	// DiscardedApplications 与保留账户同一招新季、因而被删除的申请
+	DiscardedApplications []discardedApplication `json:"discardedApplications"`
+}
+
+// discardedApplication 合并时被删除的申请
+type discardedApplication struct {
+	SeasonID    uuid.UUID           `json:"seasonId"`
+	Application applicationSnapshot `json:"application"`
+	CreatedAt   time.Time           `json:"createdAt"`
+}
+
+// errMergeRejected 合并前置检查失败
+var errMergeRejected = errors.New("merge rejected")
+
+// mergeUsers 将 merged 的所有数据并入 survivor 并删除 merged，返回各数据表的变更数量
+// 同一招新季两个账户都有申请时保留 survivor 的申请，merged 的申请内容记录在审计记录中，附件移到保留的申请下
+func mergeUsers(tx *gorm.DB, survivor, merged models.User) (*mergeDetails, error) {
+	counts := make(map[string]int64)
+	discarded := make([]discardedApplication, 0)
+
+	// 申请：每个招新季只能有一份
+	var survivorApps, mergedApps []models.Application
+	if err := tx.Where("user_id = ?", survivor.UUID).Find(&survivorApps).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", merged.UUID).Find(&mergedApps).Error; err != nil {
+		return nil, err
+	}
+	survivorAppBySeason := make(map[uuid.UUID]models.Application)
+	for _, application := range survivorApps {
+		survivorAppBySeason[application.SeasonID] = application
+	}
+	for _, application := range mergedApps {
+		kept, conflict := survivorAppBySeason[application.SeasonID]
+		if !conflict {
+			if err := tx.Model(&application).Update("user_id", survivor.UUID).Error; err != nil {
+				return nil, err
+			}
+			counts["applications.user_id"]++
+			continue
+		}
+
+		snapshot := applicationSnapshotOf(application)
+		snapshot.Answers = answerSnapshot(loadApplicationAnswers(tx, application.ID))
+
+		result := tx.Model(&models.Attachment{}).Where("application_id = ?", application.ID).Update("application_id", kept.ID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		counts["attachments.application_id"] += result.RowsAffected
+		if err := tx.Where("application_id = ?", application.ID).Delete(&models.ApplicationAnswer{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where("application_id = ?", application.ID).Delete(&models.ApplicationRevision{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Delete(&application).Error; err != nil {
+			return nil, err
+		}
+		discarded = append(discarded, discardedApplication{
+			SeasonID:    application.SeasonID,
+			Application: snapshot,
+			CreatedAt:   application.CreatedAt,
+		})
+	}
+
+	// 方向状态：同一招新季同一方向保留 survivor 的记录
+	var survivorStatuses, mergedStatuses []models.DirectionStatus
+	if err := tx.Where("user_id = ?", survivor.UUID).Find(&survivorStatuses).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", merged.UUID).Find(&mergedStatuses).Error; err != nil {
+		return nil, err
+	}
+	for _, directionStatus := range mergedStatuses {
+		conflict := slices.ContainsFunc(survivorStatuses, func(s models.DirectionStatus) bool {
+			return s.SeasonID == directionStatus.SeasonID && s.Direction == directionStatus.Direction
+		})
+		if conflict {
+			if err := tx.Delete(&directionStatus).Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := tx.Model(&directionStatus).Update("user_id", survivor.UUID).Error; err != nil {
+			return nil, err
+		}
+		counts["direction_statuses.user_id"]++
+	}
+
+	// 标签：两个账户都有的标签只保留一个
+	// MySQL 不允许删除时在子查询中引用同一张表，先查出来
+	var survivorTags []uuid.UUID
+	if err := tx.Model(&models.UserTag{}).Where("user_id = ?", survivor.UUID).Pluck("tag_id", &survivorTags).Error; err != nil {
+		return nil, err
+	}
+	if len(survivorTags) > 0 {
+		if err := tx.Where("user_id = ? AND tag_id IN ?", merged.UUID, survivorTags).Delete(&models.UserTag{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	result := tx.Model(&models.UserTag{}).Where("user_id = ?", merged.UUID).Update("user_id", survivor.UUID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	counts["user_tags.user_id"] = result.RowsAffected
+
+	// 延期：同一招新季保留截止时间较晚的一个
+	var mergedExtensions []models.ApplicationExtension
+	if err := tx.Where("user_id = ?", merged.UUID).Find(&mergedExtensions).Error; err != nil {
+		return nil, err
+	}
+	for _, extension := range mergedExtensions {
+		var kept models.ApplicationExtension
+		err := tx.Where("user_id = ? AND season_id = ?", survivor.UUID, extension.SeasonID).First(&kept).Error
+		if err == nil {
+			if extension.Until.After(kept.Until) {
+				if err := tx.Model(&kept).Updates(map[string]interface{}{"until": extension.Until, "reason": extension.Reason, "granted_by": extension.GrantedBy}).Error; err != nil {
+					return nil, err
+				}
+			}
+			if err := tx.Delete(&extension).Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err := tx.Model(&extension).Update("user_id", survivor.UUID).Error; err != nil {
+			return nil, err
+		}
+		counts["application_extensions.user_id"]++
+	}
+
+	// 草稿：同一招新季保留 survivor 的草稿
+	var survivorDrafts []uuid.UUID
+	if err := tx.Model(&models.ApplicationDraft{}).Where("user_id = ?", survivor.UUID).Pluck("season_id", &survivorDrafts).Error; err != nil {
+		return nil, err
+	}
+	if len(survivorDrafts) > 0 {
+		if err := tx.Where("user_id = ? AND season_id IN ?", merged.UUID, survivorDrafts).Delete(&models.ApplicationDraft{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	result = tx.Model(&models.ApplicationDraft{}).Where("user_id = ?", merged.UUID).Update("user_id", survivor.UUID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	counts["application_drafts.user_id"] = result.RowsAffected
+
+	// 面试预约：同一招新季同一方向只能有一个有效预约，保留 survivor 的预约
+	var survivorBookings []models.SlotBooking
+	if err := tx.Where("user_id = ? AND status = ?", survivor.UUID, "booked").Find(&survivorBookings).Error; err != nil {
+		return nil, err
+	}
+	var mergedBookings []models.SlotBooking
+	if err := tx.Where("user_id = ? AND status = ?", merged.UUID, "booked").Find(&mergedBookings).Error; err != nil {
+		return nil, err
+	}
+	for _, booking := range mergedBookings {
+		conflict := slices.ContainsFunc(survivorBookings, func(b models.SlotBooking) bool {
+			return b.SeasonID == booking.SeasonID && b.Direction == booking.Direction
+		})
+		if conflict {
+			if err := releaseBooking(tx, booking, time.Now()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	result = tx.Model(&models.SlotBooking{}).Where("user_id = ?", merged.UUID).Update("user_id", survivor.UUID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	counts["slot_bookings.user_id"] = result.RowsAffected
+
+	// 面试官分配：同一招新季同一方向保留 survivor 的分配
+	var survivorAssignments []models.Assignment
+	if err := tx.Where("user_id = ?", survivor.UUID).Find(&survivorAssignments).Error; err != nil {
+		return nil, err
+	}
+	var mergedAssignments []models.Assignment
+	if err := tx.Where("user_id = ?", merged.UUID).Find(&mergedAssignments).Error; err != nil {
+		return nil, err
+	}
+	for _, assignment := range mergedAssignments {
+		conflict := slices.ContainsFunc(survivorAssignments, func(a models.Assignment) bool {
+			return a.SeasonID == assignment.SeasonID && a.Direction == assignment.Direction
+		})
+		if conflict {
+			if err := tx.Delete(&assignment).Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := tx.Model(&assignment).Update("user_id", survivor.UUID).Error; err != nil {
+			return nil, err
+		}
+		counts["assignments.user_id"]++
+	}
+
+	// 利益冲突：面试官与面试者的组合唯一，已有相同声明的删除
+	for _, column := range []string{"user_id", "interviewer_id"} {
+		other := "interviewer_id"
+		if column == "interviewer_id" {
+			other = "user_id"
+		}
+		var survivorPairs []uuid.UUID
+		if err := tx.Model(&models.ConflictOfInterest{}).Where(column+" = ?", survivor.UUID).Pluck(other, &survivorPairs).Error; err != nil {
+			return nil, err
+		}
+		if len(survivorPairs) > 0 {
+			if err := tx.Where(column+" = ? AND "+other+" IN ?", merged.UUID, survivorPairs).Delete(&models.ConflictOfInterest{}).Error; err != nil {
+				return nil, err
+			}
+		}
+		result = tx.Model(&models.ConflictOfInterest{}).Where(column+" = ?", merged.UUID).Update(column, survivor.UUID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			counts["conflict_of_interests."+column] = result.RowsAffected
+		}
+	}
+
+	// 评分表：同一评分标准下面试官与面试者的组合唯一，保留主账户的评分表
+	for _, column := range []string{"user_id", "interviewer_id"} {
+		var survivorScorecards, mergedScorecards []models.Scorecard
+		if err := tx.Where(column+" = ?", survivor.UUID).Find(&survivorScorecards).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where(column+" = ?", merged.UUID).Find(&mergedScorecards).Error; err != nil {
+			return nil, err
+		}
+		var duplicated []uuid.UUID
+		for _, scorecard := range mergedScorecards {
+			if slices.ContainsFunc(survivorScorecards, func(s models.Scorecard) bool {
+				if s.RubricID != scorecard.RubricID {
+					return false
+				}
+				if column == "user_id" {
+					return s.InterviewerID == scorecard.InterviewerID
+				}
+				return s.UserID == scorecard.UserID
+			}) {
+				duplicated = append(duplicated, scorecard.UUID)
+			}
+		}
+		if len(duplicated) > 0 {
+			if err := deleteScorecards(tx, "uuid IN ?", duplicated); err != nil {
+				return nil, err
+			}
+		}
+		result := tx.Model(&models.Scorecard{}).Where(column+" = ?", merged.UUID).Update(column, survivor.UUID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			counts["scorecards."+column] = result.RowsAffected
+		}
+	}
+
+	// 解除匿名记录：同一招新季同一轮次只保留一条
+	var survivorReveals []models.BlindReveal
+	if err := tx.Where("user_id = ?", survivor.UUID).Find(&survivorReveals).Error; err != nil {
+		return nil, err
+	}
+	for _, reveal := range survivorReveals {
+		if err := tx.Where("user_id = ? AND season_id = ? AND round = ?", merged.UUID, reveal.SeasonID, reveal.Round).Delete(&models.BlindReveal{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	result = tx.Model(&models.BlindReveal{}).Where("user_id = ?", merged.UUID).Update("user_id", survivor.UUID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		counts["blind_reveals.user_id"] = result.RowsAffected
+	}
+
+	// 日历订阅：被合并账户的订阅链接作废
+	if err := tx.Where("user_id = ?", merged.UUID).Delete(&models.CalendarToken{}).Error; err != nil {
+		return nil, err
+	}
+
+	// 涉及被合并账户的忽略记录已无意义
+	if err := tx.Where("user_a = ? OR user_b = ?", merged.UUID, merged.UUID).Delete(&models.DuplicateDismissal{}).Error; err != nil {
+		return nil, err
+	}
+
+	for _, reference := range mergeReferences {
+		result := tx.Model(reference.Model).Where(reference.Column+" = ?", merged.UUID).Update(reference.Column, survivor.UUID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			counts[reference.Name] = result.RowsAffected
+		}
+	}
+
+	// 通过面试官取并集，方向与状态按当前招新季的申请重新计算
+	passedBy := parseJSONList(survivor.PassedDirectionsBy)
+	for _, name := range parseJSONList(merged.PassedDirectionsBy) {
+		if !slices.Contains(passedBy, name) {
+			passedBy = append(passedBy, name)
+		}
+	}
+	passedByJSON, _ := json.Marshal(passedBy)
+	updates := map[string]interface{}{"passed_directions_by": string(passedByJSON)}
+	seasonID, err := currentSeasonID(tx)
+	if err != nil {
+		return nil, err
+	}
+	var current models.Application
+	if err := tx.Where("user_id = ? AND season_id = ?", survivor.UUID, seasonID).First(&current).Error; err == nil {
+		updates["directions"] = current.Directions
+	}
+	if err := tx.Model(&survivor).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := syncDirectionStatuses(tx, survivor.UUID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Delete(&merged).Error; err != nil {
+		return nil, err
+	}
+
+	return &mergeDetails{Counts: counts, DiscardedApplications: discarded}, nil
+}
+
+// MergeUsersRequest 合并账户请求
+type MergeUsersRequest struct {
+	SurvivorID string `json:"survivorId" binding:"required"`
+	MergedID   string `json:"mergedId" binding:"required"`
+}
+
+// MergeUsers 将重复的账户合并到保留的账户（管理员），在一个事务中完成并写入审计记录
+func MergeUsers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req MergeUsersRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		adminUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		survivorUUID, err := uuid.Parse(req.SurvivorID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "survivorId 参数校验失败"})
+			return
+		}
+		mergedUUID, err := uuid.Parse(req.MergedID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "mergedId 参数校验失败"})
+			return
+		}
+		if survivorUUID == mergedUUID {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "不能合并同一个账户"})
+			return
+		}
+		if mergedUUID == adminUUID {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "不能合并自己的账户"})
+			return
+		}
+
+		var recordUUID uuid.UUID
+		var details *mergeDetails
+		code, message := http.StatusOK, ""
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var survivor, merged models.User
+			if err := tx.Where("uuid = ?", survivorUUID).First(&survivor).Error; err != nil {
+				code, message = http.StatusNotFound, "保留的账户不存在"
+				return errMergeRejected
+			}
+			if err := tx.Where("uuid = ?", mergedUUID).First(&merged).Error; err != nil {
+				code, message = http.StatusNotFound, "被合并的账户不存在"
+				return errMergeRejected
+			}
+			if survivor.Role != merged.Role {
+				code, message = http.StatusBadRequest, "只能合并相同角色的账户"
+				return errMergeRejected
+			}
+
+			var err error
+			details, err = mergeUsers(tx, survivor, merged)
+			if err != nil {
+				return err
+			}
+
+			nickname := ""
+			if merged.Nickname != nil {
+				nickname = *merged.Nickname
+			}
+			detailsJSON, _ := json.Marshal(details)
+			recordUUID, _ = uuid.NewUUID()
+			return tx.Create(&models.MergeRecord{
+				UUID:           recordUUID,
+				SurvivorID:     survivor.UUID,
+				MergedID:       merged.UUID,
+				MergedEmail:    merged.Email,
+				MergedNickname: nickname,
+				MergedBy:       adminUUID,
+				Details:        string(detailsJSON),
+			}).Error
+		})
+		if errors.Is(err, errMergeRejected) {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id":      recordUUID.String(),
+				"details": mergeDetailsData(*details),
+			},
+		})
+	}
+}
+
+// mergeDetailsData 构建合并详情的返回数据
+func mergeDetailsData(details mergeDetails) gin.H {
+	discarded := make([]gin.H, 0, len(details.DiscardedApplications))
+	for _, item := range details.DiscardedApplications {
+		application := gin.H{}
+		for field, value := range item.Application.values() {
+			application[field] = template.HTMLEscapeString(value)
+		}
+		discarded = append(discarded, gin.H{
+			"seasonId":    item.SeasonID.String(),
+			"application": application,
+			"createdAt":   item.CreatedAt,
+		})
+	}
+	counts := details.Counts
+	if counts == nil {
+		counts = map[string]int64{}
+	}
+	return gin.H{
+		"counts":                counts,
+		"discardedApplications": discarded,
+	}
+}
+
+// GetMergeRecords 获取账户合并记录（管理员）
+func GetMergeRecords(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var records []models.MergeRecord
+		if err := db.Order("created_at DESC").Find(&records).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		userIds := make([]uuid.UUID, 0, len(records)*2)
+		for _, record := range records {
+			userIds = append(userIds, record.SurvivorID, record.MergedBy)
+		}
+		userNames := loadUserNames(db, userIds)
+
+		items := make([]gin.H, 0, len(records))
+		for _, record := range records {
+			var details mergeDetails
+			json.Unmarshal([]byte(record.Details), &details)
+			items = append(items, gin.H{
+				"id":             record.UUID.String(),
+				"survivorId":     record.SurvivorID.String(),
+				"survivorName":   template.HTMLEscapeString(userNames[record.SurvivorID.String()]),
+				"mergedId":       record.MergedID.String(),
+				"mergedEmail":    template.HTMLEscapeString(record.MergedEmail),
+				"mergedNickname": template.HTMLEscapeString(record.MergedNickname),
+				"mergedBy":       template.HTMLEscapeString(userNames[record.MergedBy.String()]),
+				"details":        mergeDetailsData(details),
+				"createdAt":      record.CreatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"time"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetPipeline 获取按面试状态分列的看板（面试官），可按方向查看
+func GetPipeline(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		direction := c.Query("direction")
+		if direction != "" && !auth.ValidateDirection(direction) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "方向参数校验失败"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		// 只展示当前招新季的申请
+		tx := db.Where("role = ?", "interviewee").Preload("Application", "season_id = ?", seasonID)
+		if direction != "" {
+			tx = tx.Where("JSON_CONTAINS(directions, ?)", fmt.Sprintf("\"%s\"", direction))
+		}
+
+		var users []models.User
+		if err := tx.Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		userIds := make([]uuid.UUID, 0, len(users))
+		for _, user := range users {
+			userIds = append(userIds, user.UUID)
+		}
+		userTagsMap := loadUserTags(db, userIds)
+		lastActivityMap := loadLastActivity(db, users)
+
+		// 方向看板按该方向的状态分列，总看板按汇总状态分列
+		columnStatus := make(map[uuid.UUID]string)
+		for _, user := range users {
+			columnStatus[user.UUID] = user.Status
+		}
+		if direction != "" && len(userIds) > 0 {
+			var directionStatuses []models.DirectionStatus
+			if err := db.Where("user_id IN ? AND direction = ? AND season_id = ?", userIds, direction, seasonID).Find(&directionStatuses).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			for _, directionStatus := range directionStatuses {
+				columnStatus[directionStatus.UserID] = directionStatus.Status
+			}
+		}
+
+		// 按状态分组卡片，匿名评审轮次中的面试者显示化名
+		blind := loadBlindView(db, c, seasonID)
+		cardsByStatus := make(map[string][]gin.H)
+		for _, user := range users {
+			nickname := ""
+			if user.Nickname != nil {
+				nickname = *user.Nickname
+			}
+			masked := blind.masked(user)
+			if masked {
+				nickname = pseudonym(seasonID, user.UUID)
+			}
+
+			tags, exists := userTagsMap[user.UUID]
+			if !exists {
+				tags = []gin.H{}
+			}
+
+			status := columnStatus[user.UUID]
+			cardsByStatus[status] = append(cardsByStatus[status], gin.H{
+				"id":           user.UUID.String(),
+				"nickname":     template.HTMLEscapeString(nickname),
+				"directions":   parseJSONList(user.Directions),
+				"tags":         tags,
+				"status":       user.Status,
+				"blind":        masked,
+				"lastActivity": lastActivityMap[user.UUID],
+			})
+		}
+
+		// 列按状态定义的顺序排列
+		definitions, err := loadStatusDefinitions(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		// 每列内按最近活动时间倒序
+		columns := make([]gin.H, 0, len(definitions))
+		for _, definition := range definitions {
+			cards := cardsByStatus[definition.Key]
+			if cards == nil {
+				cards = []gin.H{}
+			}
+			slices.SortFunc(cards, func(a, b gin.H) int {
+				return b["lastActivity"].(time.Time).Compare(a["lastActivity"].(time.Time))
+			})
+			columns = append(columns, gin.H{
+				"status": definition.Key,
+				"label":  template.HTMLEscapeString(definition.Label),
+				"round":  definition.Round,
+				"count":  len(cards),
+				"cards":  cards,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"direction": direction,
+				"total":     len(users),
+				"columns":   columns,
+			},
+		})
+	}
+}
+
+// MovePipelineCardRequest 看板移动卡片请求
+type MovePipelineCardRequest struct {
+	UserID    string `json:"userId" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Direction string `json:"direction"`
+	Reason    string `json:"reason" binding:"max=200"`
+	Force     bool   `json:"force"`
+}
+
+// MovePipelineCard 在看板中移动卡片（面试官），与 SetInterviewStatus 使用相同的校验
+// 指定方向时只修改该方向的状态
+func MovePipelineCard(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req MovePipelineCardRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		currentUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		// 跳过流转校验需要管理员权限
+		if req.Force && !IsAdmin(db, currentUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "强制修改状态需要管理员权限"})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("uuid = ?", userUUID).First(&user).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "用户不存在"})
+			return
+		}
+
+		// 在方向看板中操作时只修改该方向的状态
+		change := statusChange{Status: req.Status, Direction: req.Direction, Reason: req.Reason, ActorID: currentUUID, Force: req.Force}
+		if err := changeInterviewStatus(db, &user, change); err != nil {
+			code, message := statusErrorResponse(err)
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// loadLastActivity 计算每个面试者的最近活动时间（资料、申请、任务、评论中最新的一次）
+func loadLastActivity(db *gorm.DB, users []models.User) map[uuid.UUID]time.Time {
+	result := make(map[uuid.UUID]time.Time)
+	userIds := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		result[user.UUID] = user.UpdatedAt
+		if user.Application != nil && user.Application.UpdatedAt.After(result[user.UUID]) {
+			result[user.UUID] = user.Application.UpdatedAt
+		}
+		userIds = append(userIds, user.UUID)
+	}
+	if len(userIds) == 0 {
+		return result
+	}
+
+	type activity struct {
+		UserID uuid.UUID
+		Latest time.Time
+	}
+	merge := func(rows []activity) {
+		for _, row := range rows {
+			if row.Latest.After(result[row.UserID]) {
+				result[row.UserID] = row.Latest
+			}
+		}
+	}
+
+	var taskRows []activity
+	if err := db.Model(&models.Task{}).
+		Select("target_user_id AS user_id, MAX(updated_at) AS latest").
+		Where("target_user_id IN ?", userIds).
+		Group("target_user_id").
+		Scan(&taskRows).Error; err == nil {
+		merge(taskRows)
+	}
+
+	var commentRows []activity
+	if err := db.Model(&models.Comment{}).
+		Select("interviewee_id AS user_id, MAX(updated_at) AS latest").
+		Where("interviewee_id IN ?", userIds).
+		Group("interviewee_id").
+		Scan(&commentRows).Error; err == nil {
+		merge(commentRows)
+	}
+
+	return result
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// divergenceRatio 同一面试者的最高分与最低分之差超过分值范围的该比例时标记为需要讨论
+const divergenceRatio = 0.3
+
+// rankingScore 某个面试官给出的总分及其标准化分数
+type rankingScore struct {
+	InterviewerID uuid.UUID
+	Total         float64
+	ZScore        float64
+}
+
+// rankingEntry 面试者在某个方向和轮次的汇总成绩
+type rankingEntry struct {
+	Rank       int
+	UserID     uuid.UUID
+	RawAverage float64
+	ZAverage   float64
+	Spread     float64
+	Range      float64
+	Divergent  bool
+	Scores     []rankingScore
+}
+
+// interviewerCalibration 面试官的打分习惯，Bias 为其平均分与全体平均分之差
+type interviewerCalibration struct {
+	InterviewerID uuid.UUID
+	Count         int
+	Mean          float64
+	Stddev        float64
+	Bias          float64
+}
+
+// ranking 某个评分标准下的排名与校准结果
+type ranking struct {
+	Rubric       models.Rubric
+	Entries      []rankingEntry
+	Interviewers []interviewerCalibration
+	Mean         float64
+}
+
+// round2 保留两位小数
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// buildRanking 计算评分标准下所有面试者的排名
+func buildRanking(db *gorm.DB, rubric models.Rubric) (*ranking, error) {
+	var scorecards []models.Scorecard
+	if err := db.Where("rubric_id = ?", rubric.UUID).Order("created_at ASC").Find(&scorecards).Error; err != nil {
+		return nil, err
+	}
+	return rankScorecards(rubric, scorecards), nil
+}
+
+// rankScorecards 按评分表计算排名与校准结果
+// 每个面试官的总分按其自身的平均分和标准差转换为 z 分数，消除打分宽严的差异；
+// 面试官只有一份评分表或所有评分相同时无法标准化，z 分数记为 0
+func rankScorecards(rubric models.Rubric, scorecards []models.Scorecard) *ranking {
+	result := &ranking{Rubric: rubric, Entries: []rankingEntry{}, Interviewers: []interviewerCalibration{}}
+	if len(scorecards) == 0 {
+		return result
+	}
+
+	totals := make([]float64, 0, len(scorecards))
+	totalsByInterviewer := make(map[uuid.UUID][]float64)
+	interviewerOrder := make([]uuid.UUID, 0)
+	for _, scorecard := range scorecards {
+		totals = append(totals, scorecard.Total)
+		if _, exists := totalsByInterviewer[scorecard.InterviewerID]; !exists {
+			interviewerOrder = append(interviewerOrder, scorecard.InterviewerID)
+		}
+		totalsByInterviewer[scorecard.InterviewerID] = append(totalsByInterviewer[scorecard.InterviewerID], scorecard.Total)
+	}
+	result.Mean, _ = meanStddev(totals)
+
+	calibrations := make(map[uuid.UUID]interviewerCalibration, len(interviewerOrder))
+	for _, interviewerUUID := range interviewerOrder {
+		values := totalsByInterviewer[interviewerUUID]
+		mean, stddev := meanStddev(values)
+		calibration := interviewerCalibration{
+			InterviewerID: interviewerUUID,
+			Count:         len(values),
+			Mean:          mean,
+			Stddev:        stddev,
+			Bias:          mean - result.Mean,
+		}
+		calibrations[interviewerUUID] = calibration
+		result.Interviewers = append(result.Interviewers, calibration)
+	}
+
+	entries := make(map[uuid.UUID]*rankingEntry)
+	entryOrder := make([]uuid.UUID, 0)
+	for _, scorecard := range scorecards {
+		calibration := calibrations[scorecard.InterviewerID]
+		zScore := 0.0
+		if calibration.Count > 1 && calibration.Stddev > 0 {
+			zScore = (scorecard.Total - calibration.Mean) / calibration.Stddev
+		}
+		entry, exists := entries[scorecard.UserID]
+		if !exists {
+			entry = &rankingEntry{UserID: scorecard.UserID}
+			entries[scorecard.UserID] = entry
+			entryOrder = append(entryOrder, scorecard.UserID)
+		}
+		entry.Scores = append(entry.Scores, rankingScore{
+			InterviewerID: scorecard.InterviewerID,
+			Total:         scorecard.Total,
+			ZScore:        zScore,
+		})
+	}
+
+	span := float64(rubric.ScaleMax - rubric.ScaleMin)
+	for _, userUUID := range entryOrder {
+		entry := entries[userUUID]
+		raw := make([]float64, 0, len(entry.Scores))
+		normalized := make([]float64, 0, len(entry.Scores))
+		for _, score := range entry.Scores {
+			raw = append(raw, score.Total)
+			normalized = append(normalized, score.ZScore)
+		}
+		entry.RawAverage, entry.Spread = meanStddev(raw)
+		entry.ZAverage, _ = meanStddev(normalized)
+		entry.Range = math.Abs(maxOf(raw) - minOf(raw))
+		entry.Divergent = len(raw) > 1 && entry.Range > span*divergenceRatio
+		result.Entries = append(result.Entries, *entry)
+	}
+
+	// 按标准化均分排序，相同时按原始均分
+	sort.SliceStable(result.Entries, func(i, j int) bool {
+		if result.Entries[i].ZAverage != result.Entries[j].ZAverage {
+			return result.Entries[i].ZAverage > result.Entries[j].ZAverage
+		}
+		return result.Entries[i].RawAverage > result.Entries[j].RawAverage
+	})
+	for i := range result.Entries {
+		result.Entries[i].Rank = i + 1
+	}
+	return result
+}
+
+// maxOf 返回最大值
+func maxOf(values []float64) float64 {
+	result := values[0]
+	for _, value := range values[1:] {
+		result = math.Max(result, value)
+	}
+	return result
+}
+
+// minOf 返回最小值
+func minOf(values []float64) float64 {
+	result := values[0]
+	for _, value := range values[1:] {
+		result = math.Min(result, value)
+	}
+	return result
+}
+
+// agreement 面试官之间的一致性：只统计有多份评分表的面试者
+func (r *ranking) agreement() gin.H {
+	var spreads, ranges []float64
+	divergent := 0
+	for _, entry := range r.Entries {
+		if len(entry.Scores) < 2 {
+			continue
+		}
+		spreads = append(spreads, entry.Spread)
+		ranges = append(ranges, entry.Range)
+		if entry.Divergent {
+			divergent++
+		}
+	}
+	meanSpread, _ := meanStddev(spreads)
+	meanRange, _ := meanStddev(ranges)
+	return gin.H{
+		"candidates":      len(r.Entries),
+		"multiScored":     len(spreads),
+		"meanSpread":      round2(meanSpread),
+		"meanRange":       round2(meanRange),
+		"divergent":       divergent,
+		"divergenceRange": round2(float64(r.Rubric.ScaleMax-r.Rubric.ScaleMin) * divergenceRatio),
+	}
+}
+
+// GetRankings 获取某个方向和轮次的排名（面试官）
+// 包含原始均分、按面试官校准后的标准化均分、各面试官的打分习惯与一致性统计，分歧较大的面试者标记为需要讨论
+func GetRankings(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		round, err := strconv.Atoi(c.Query("round"))
+		if err != nil || c.Query("direction") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var rubric models.Rubric
+		if err := db.Where("season_id = ? AND direction = ? AND round = ?", season.UUID, c.Query("direction"), round).First(&rubric).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该方向和轮次没有评分标准"})
+			return
+		}
+
+		result, err := buildRanking(db, rubric)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		userIds := make([]uuid.UUID, 0, len(result.Entries)+len(result.Interviewers))
+		for _, entry := range result.Entries {
+			userIds = append(userIds, entry.UserID)
+		}
+		for _, calibration := range result.Interviewers {
+			userIds = append(userIds, calibration.InterviewerID)
+		}
+		userNames := loadUserNames(db, userIds)
+		// 匿名评审轮次中的面试者显示化名
+		loadSeasonBlindView(db, c, season.UUID).maskNames(db, userNames)
+
+		items := make([]gin.H, 0, len(result.Entries))
+		for _, entry := range result.Entries {
+			name := userNames[entry.UserID.String()]
+			scores := make([]gin.H, 0, len(entry.Scores))
+			for _, score := range entry.Scores {
+				scores = append(scores, gin.H{
+					"interviewerId":   score.InterviewerID.String(),
+					"interviewerName": template.HTMLEscapeString(userNames[score.InterviewerID.String()]),
+					"total":           score.Total,
+					"zScore":          round2(score.ZScore),
+				})
+			}
+			items = append(items, gin.H{
+				"rank":       entry.Rank,
+				"userId":     entry.UserID.String(),
+				"userName":   template.HTMLEscapeString(name),
+				"count":      len(entry.Scores),
+				"rawAverage": round2(entry.RawAverage),
+				"zAverage":   round2(entry.ZAverage),
+				"spread":     round2(entry.Spread),
+				"range":      round2(entry.Range),
+				"divergent":  entry.Divergent,
+				"scores":     scores,
+			})
+		}
+
+		interviewers := make([]gin.H, 0, len(result.Interviewers))
+		for _, calibration := range result.Interviewers {
+			interviewers = append(interviewers, gin.H{
+				"interviewerId":   calibration.InterviewerID.String(),
+				"interviewerName": template.HTMLEscapeString(userNames[calibration.InterviewerID.String()]),
+				"count":           calibration.Count,
+				"mean":            round2(calibration.Mean),
+				"stddev":          round2(calibration.Stddev),
+				"bias":            round2(calibration.Bias),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"rubric":       rubricData(rubric, loadRubricCriteria(db, []uuid.UUID{rubric.UUID})[rubric.UUID]),
+				"mean":         round2(result.Mean),
+				"agreement":    result.agreement(),
+				"interviewers": interviewers,
+				"items":        items,
+			},
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestMeanStddev(t *testing.T) {
+	tests := []struct {
+		values []float64
+		mean   float64
+		stddev float64
+	}{
+		{nil, 0, 0},
+		{[]float64{5}, 5, 0},
+		{[]float64{9, 7}, 8, 1},
+		{[]float64{2, 4, 4, 4, 5, 5, 7, 9}, 5, 2},
+	}
+
+	for _, tt := range tests {
+		mean, stddev := meanStddev(tt.values)
+		if math.Abs(mean-tt.mean) > 1e-9 || math.Abs(stddev-tt.stddev) > 1e-9 {
+			t.Errorf("meanStddev(%v) = %v, %v, want %v, %v", tt.values, mean, stddev, tt.mean, tt.stddev)
+		}
+	}
+}
+
+func TestRankScorecards(t *testing.T) {
+	rubric := models.Rubric{ScaleMin: 1, ScaleMax: 10}
+	lenient, strict := uuid.New(), uuid.New()
+	u1, u2, u3 := uuid.New(), uuid.New(), uuid.New()
+
+	// 宽松的面试官平均 8 分，严格的面试官平均 4 分，标准差都是 1
+	result := rankScorecards(rubric, []models.Scorecard{
+		{InterviewerID: lenient, UserID: u1, Total: 9},
+		{InterviewerID: lenient, UserID: u2, Total: 7},
+		{InterviewerID: strict, UserID: u1, Total: 5},
+		{InterviewerID: strict, UserID: u3, Total: 3},
+	})
+
+	if result.Mean != 6 {
+		t.Errorf("Mean = %v, want 6", result.Mean)
+	}
+
+	wantCalibrations := map[uuid.UUID]interviewerCalibration{
+		lenient: {InterviewerID: lenient, Count: 2, Mean: 8, Stddev: 1, Bias: 2},
+		strict:  {InterviewerID: strict, Count: 2, Mean: 4, Stddev: 1, Bias: -2},
+	}
+	if len(result.Interviewers) != len(wantCalibrations) {
+		t.Fatalf("len(Interviewers) = %d, want %d", len(result.Interviewers), len(wantCalibrations))
+	}
+	for _, calibration := range result.Interviewers {
+		if want := wantCalibrations[calibration.InterviewerID]; calibration != want {
+			t.Errorf("calibration = %+v, want %+v", calibration, want)
+		}
+	}
+
+	// u2 与 u3 的标准化均分相同，按原始均分排序
+	wantEntries := []struct {
+		userID     uuid.UUID
+		rawAverage float64
+		zAverage   float64
+		spread     float64
+		rangeValue float64
+		divergent  bool
+	}{
+		{u1, 7, 1, 2, 4, true},
+		{u2, 7, -1, 0, 0, false},
+		{u3, 3, -1, 0, 0, false},
+	}
+	if len(result.Entries) != len(wantEntries) {
+		t.Fatalf("len(Entries) = %d, want %d", len(result.Entries), len(wantEntries))
+	}
+	for i, want := range wantEntries {
+		entry := result.Entries[i]
+		if entry.Rank != i+1 || entry.UserID != want.userID || entry.RawAverage != want.rawAverage ||
+			entry.ZAverage != want.zAverage || entry.Spread != want.spread || entry.Range != want.rangeValue ||
+			entry.Divergent != want.divergent {
+			t.Errorf("Entries[%d] = %+v, want rank %d %+v", i, entry, i+1, want)
+		}
+	}
+
+	agreement := result.agreement()
+	wantAgreement := gin.H{
+		"candidates":      3,
+		"multiScored":     1,
+		"meanSpread":      2.0,
+		"meanRange":       4.0,
+		"divergent":       1,
+		"divergenceRange": 2.7,
+	}
+	for key, want := range wantAgreement {
+		if agreement[key] != want {
+			t.Errorf("agreement[%q] = %v, want %v", key, agreement[key], want)
+		}
+	}
+}
+
+func TestRankScorecardsWithoutSpread(t *testing.T) {
+	rubric := models.Rubric{ScaleMin: 1, ScaleMax: 5}
+	single, uniform := uuid.New(), uuid.New()
+	u1, u2 := uuid.New(), uuid.New()
+
+	// 只有一份评分或所有评分相同的面试官无法标准化，z 分数记为 0
+	result := rankScorecards(rubric, []models.Scorecard{
+		{InterviewerID: single, UserID: u1, Total: 5},
+		{InterviewerID: uniform, UserID: u1, Total: 3},
+		{InterviewerID: uniform, UserID: u2, Total: 3},
+	})
+
+	for _, entry := range result.Entries {
+		for _, score := range entry.Scores {
+			if score.ZScore != 0 {
+				t.Errorf("ZScore for %v = %v, want 0", score.InterviewerID, score.ZScore)
+			}
+		}
+	}
+	if result.Entries[0].UserID != u1 || result.Entries[1].UserID != u2 {
+		t.Errorf("entries should fall back to raw average order")
+	}
+	// 分值范围 4 的 30% 为 1.2，u1 的分差为 2
+	if !result.Entries[0].Divergent {
+		t.Errorf("u1 should be divergent: %+v", result.Entries[0])
+	}
+
+	if empty := rankScorecards(rubric, nil); len(empty.Entries) != 0 || len(empty.Interviewers) != 0 {
+		t.Errorf("rankScorecards(nil) = %+v, want empty ranking", empty)
+	}
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"xdsec-join-2026/models"
+	"xdsec-join-2026/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// latestReportVersion 任务报告的最新版本号，没有版本时为 0
+func latestReportVersion(tx *gorm.DB, taskUUID uuid.UUID) (int, error) {
+	var latest int
+	err := tx.Model(&models.TaskReportVersion{}).
+		Where("task_id = ?", taskUUID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error
+	return latest, err
+}
+
+// createReportVersion 记录任务报告的新版本，版本记录只增不改，返回新版本号
+// 先锁定任务再取最大版本号，避免并发写入得到相同的版本号
+func createReportVersion(tx *gorm.DB, task models.Task, report string, late bool, createdAt time.Time) (int, error) {
+	if err := lockTask(tx, task.UUID); err != nil {
+		return 0, err
+	}
+	latest, err := latestReportVersion(tx, task.UUID)
+	if err != nil {
+		return 0, err
+	}
+
+	versionUUID, _ := uuid.NewUUID()
+	version := models.TaskReportVersion{
+		UUID:      versionUUID,
+		TaskID:    task.UUID,
+		UserID:    task.TargetUserId,
+		Version:   latest + 1,
+		Report:    report,
+		Late:      late,
+		CreatedAt: createdAt,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return 0, err
+	}
+	return version.Version, nil
+}
+
+// lockTask 锁定任务行，串行化同一任务的版本写入
+func lockTask(tx *gorm.DB, taskUUID uuid.UUID) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("uuid").Where("uuid = ?", taskUUID).First(&models.Task{}).Error
+}
+
+// ensureBaselineReportVersion 为没有版本记录的旧报告补一条初始版本
+func ensureBaselineReportVersion(tx *gorm.DB, task models.Task) error {
+	if task.Report == "" {
+		return nil
+	}
+	if err := lockTask(tx, task.UUID); err != nil {
+		return err
+	}
+	latest, err := latestReportVersion(tx, task.UUID)
+	if err != nil || latest > 0 {
+		return err
+	}
+	createdAt := task.UpdatedAt
+	if task.SubmittedAt != nil {
+		createdAt = *task.SubmittedAt
+	}
+	version, err := createReportVersion(tx, task, task.Report, task.Late, createdAt)
+	if err != nil || task.ReviewedBy == nil || task.ReviewedAt == nil {
+		return err
+	}
+	// 补录的初始版本保留原有的批阅信息
+	return tx.Model(&models.TaskReportVersion{}).
+		Where("task_id = ? AND version = ?", task.UUID, version).
+		Updates(map[string]interface{}{"review_status": task.Status, "reviewed_by": *task.ReviewedBy, "reviewed_at": *task.ReviewedAt}).Error
+}
+
+// SeedReportVersions 为还没有版本记录的旧报告补录初始版本
+func SeedReportVersions(db *gorm.DB) {
+	var tasks []models.Task
+	err := db.Where("report <> '' AND NOT EXISTS (?)",
+		db.Model(&models.TaskReportVersion{}).Select("1").Where("task_report_versions.task_id = tasks.uuid")).
+		FindInBatches(&tasks, 200, func(_ *gorm.DB, _ int) error {
+			for _, task := range tasks {
+				if err := db.Transaction(func(tx *gorm.DB) error {
+					return ensureBaselineReportVersion(tx, task)
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		log.Printf("补录任务报告版本失败: %v", err)
+	}
+}
+
+// markReportVersionReviewed 在批阅的版本上记录批阅结果
+func markReportVersionReviewed(tx *gorm.DB, task models.Task, version int, status string, reviewerUUID uuid.UUID, reviewedAt time.Time) error {
+	return tx.Model(&models.TaskReportVersion{}).
+		Where("task_id = ? AND version = ?", task.UUID, version).
+		Updates(map[string]interface{}{"review_status": status, "reviewed_by": reviewerUUID, "reviewed_at": reviewedAt}).Error
+}
+
+// loadLatestReportVersions 批量查询任务报告的最新版本号
+func loadLatestReportVersions(db *gorm.DB, taskIds []uuid.UUID) map[uuid.UUID]int {
+	result := make(map[uuid.UUID]int)
+	if len(taskIds) == 0 {
+		return result
+	}
+	var rows []struct {
+		TaskID  uuid.UUID
+		Version int
+	}
+	db.Model(&models.TaskReportVersion{}).
+		Select("task_id, MAX(version) AS version").
+		Where("task_id IN ?", taskIds).
+		Group("task_id").
+		Scan(&rows)
+	for _, row := range rows {
+		result[row.TaskID] = row.Version
+	}
+	return result
+}
+
+// loadTaskParam 按 id 参数查询任务
+func loadTaskParam(db *gorm.DB, c *gin.Context) (*models.Task, int, string) {
+	taskUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, http.StatusBadRequest, "参数校验失败"
+	}
+	var task models.Task
+	if err := db.Where("uuid = ?", taskUUID).First(&task).Error; err != nil {
+		return nil, http.StatusNotFound, "任务不存在"
+	}
+	return &task, http.StatusOK, ""
+}
+
+// GetTaskReportVersions 获取任务报告的所有版本（面试官）
+// 最新版本为当前批阅的版本，批阅后又提交的版本可以据此看出
+func GetTaskReportVersions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		task, code, message := loadTaskParam(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var versions []models.TaskReportVersion
+		if err := db.Where("task_id = ?", task.UUID).Order("version DESC").Find(&versions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		reviewerIds := make([]uuid.UUID, 0, len(versions))
+		for _, version := range versions {
+			if version.ReviewedBy != nil {
+				reviewerIds = append(reviewerIds, *version.ReviewedBy)
+			}
+		}
+		reviewerNames := loadUserNames(db, reviewerIds)
+
+		// 最新版本在前
+		items := make([]gin.H, 0, len(versions))
+		for i, version := range versions {
+			item := gin.H{
+				"version":      version.Version,
+				"report":       version.Report,
+				"late":         version.Late,
+				"current":      i == 0,
+				"reviewStatus": version.ReviewStatus,
+				"reviewedAt":   version.ReviewedAt,
+				"createdAt":    version.CreatedAt,
+			}
+			if version.ReviewedBy != nil {
+				item["reviewedBy"] = template.HTMLEscapeString(reviewerNames[version.ReviewedBy.String()])
+			}
+			items = append(items, item)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// GetTaskReportDiff 获取任务报告任意两个版本之间的逐行差异（面试官）
+// 未指定 to 时为最新版本，未指定 from 时为 to 的上一个版本
+func GetTaskReportDiff(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		task, code, message := loadTaskParam(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		latest, err := latestReportVersion(db, task.UUID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if latest == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该任务还没有提交报告"})
+			return
+		}
+
+		toVersion := latest
+		if raw := c.Query("to"); raw != "" {
+			version, err := strconv.Atoi(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "to 参数校验失败"})
+				return
+			}
+			toVersion = version
+		}
+		fromVersion := toVersion - 1
+		if raw := c.Query("from"); raw != "" {
+			version, err := strconv.Atoi(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "from 参数校验失败"})
+				return
+			}
+			fromVersion = version
+		}
+
+		// from 为 0 时与空报告比较
+		fromReport := ""
+		if fromVersion > 0 {
+			var version models.TaskReportVersion
+			if err := db.Where("task_id = ? AND version = ?", task.UUID, fromVersion).First(&version).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "版本不存在"})
+				return
+			}
+			fromReport = version.Report
+		}
+		var toReport models.TaskReportVersion
+		if err := db.Where("task_id = ? AND version = ?", task.UUID, toVersion).First(&toReport).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "版本不存在"})
+			return
+		}
+
+		lines := utils.DiffLines(fromReport, toReport.Report)
+		for i := range lines {
+			lines[i].Text = template.HTMLEscapeString(lines[i].Text)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"from":  fromVersion,
+				"to":    toVersion,
+				"lines": lines,
+			},
+		})
+	}
+}
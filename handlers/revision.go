@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"xdsec-join-2026/models"
+	"xdsec-join-2026/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// applicationSnapshot 申请在某个版本的完整内容
+type applicationSnapshot struct {
+	RealName   string   `json:"realName"`
+	Phone      string   `json:"phone"`
+	Gender     string   `json:"gender"`
+	Department string   `json:"department"`
+	Major      string   `json:"major"`
+	StudentId  string   `json:"studentId"`
+	Directions []string `json:"directions"`
+	Resume     string   `json:"resume"`
+	// Answers 自定义问题的回答，以问题ID为键
+	Answers map[string]string `json:"answers,omitempty"`
+}
+
+// snapshotFields 参与比较的字段，按展示顺序排列
+var snapshotFields = []string{"realName", "phone", "gender", "department", "major", "studentId", "directions", "resume"}
+
+// applicationSnapshotOf 由申请记录生成快照
+func applicationSnapshotOf(application models.Application) applicationSnapshot {
+	return applicationSnapshot{
+		RealName:   application.RealName,
+		Phone:      application.Phone,
+		Gender:     application.Gender,
+		Department: application.Department,
+		Major:      application.Major,
+		StudentId:  application.StudentId,
+		Directions: parseJSONList(application.Directions),
+		Resume:     application.Resume,
+	}
+}
+
+// answerSnapshot 将回答转换为快照中的形式
+func answerSnapshot(values map[uuid.UUID]string) map[string]string {
+	answers := make(map[string]string, len(values))
+	for questionID, value := range values {
+		answers[questionID.String()] = value
+	}
+	return answers
+}
+
+// values 快照中各字段的文本值，回答以 answers.<问题ID> 为字段名
+func (s applicationSnapshot) values() map[string]string {
+	values := map[string]string{
+		"realName":   s.RealName,
+		"phone":      s.Phone,
+		"gender":     s.Gender,
+		"department": s.Department,
+		"major":      s.Major,
+		"studentId":  s.StudentId,
+		"directions": strings.Join(s.Directions, ", "),
+		"resume":     s.Resume,
+	}
+	for questionID, value := range s.Answers {
+		values["answers."+questionID] = value
+	}
+	return values
+}
+
+// changedFields 与另一个快照相比发生变化的字段
+func (s applicationSnapshot) changedFields(other applicationSnapshot) []string {
+	from := s.values()
+	to := other.values()
+	fields := make([]string, 0)
+	for _, field := range snapshotFields {
+		if from[field] != to[field] {
+			fields = append(fields, field)
+		}
+	}
+
+	// 回答按问题ID排序，保证结果稳定
+	answerFields := make([]string, 0)
+	for field := range from {
+		if strings.HasPrefix(field, "answers.") {
+			answerFields = append(answerFields, field)
+		}
+	}
+	for field := range to {
+		if _, exists := from[field]; !exists && strings.HasPrefix(field, "answers.") {
+			answerFields = append(answerFields, field)
+		}
+	}
+	slices.Sort(answerFields)
+	for _, field := range answerFields {
+		if from[field] != to[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// parseSnapshot 解析修订记录中的快照
+func parseSnapshot(revision models.ApplicationRevision) applicationSnapshot {
+	var snapshot applicationSnapshot
+	json.Unmarshal([]byte(revision.Snapshot), &snapshot)
+	return snapshot
+}
+
+// createApplicationRevision 记录申请的新版本，修订记录只增不改
+func createApplicationRevision(tx *gorm.DB, application models.Application, snapshot applicationSnapshot, authorID uuid.UUID, createdAt time.Time) error {
+	var latest int
+	if err := tx.Model(&models.ApplicationRevision{}).
+		Where("application_id = ?", application.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	snapshotJSON, _ := json.Marshal(snapshot)
+	revisionUUID, _ := uuid.NewUUID()
+	return tx.Create(&models.ApplicationRevision{
+		UUID:          revisionUUID,
+		ApplicationID: application.ID,
+		UserID:        application.UserID,
+		SeasonID:      application.SeasonID,
+		Version:       latest + 1,
+		Snapshot:      string(snapshotJSON),
+		AuthorID:      authorID,
+		CreatedAt:     createdAt,
+	}).Error
+}
+
+// ensureBaselineRevision 为没有修订记录的旧申请补一条初始版本
+func ensureBaselineRevision(tx *gorm.DB, application models.Application) error {
+	var count int64
+	if err := tx.Model(&models.ApplicationRevision{}).Where("application_id = ?", application.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	snapshot := applicationSnapshotOf(application)
+	snapshot.Answers = answerSnapshot(loadApplicationAnswers(tx, application.ID))
+	return createApplicationRevision(tx, application, snapshot, application.UserID, application.UpdatedAt)
+}
+
+// loadSeasonApplication 按 userId 参数与 season 参数查询申请
+func loadSeasonApplication(db *gorm.DB, c *gin.Context) (*models.Application, int, string) {
+	userUUID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return nil, http.StatusBadRequest, "id 参数校验失败"
+	}
+
+	season, code, message := resolveSeason(db, c)
+	if code != http.StatusOK {
+		return nil, code, message
+	}
+
+	var application models.Application
+	if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).First(&application).Error; err != nil {
+		return nil, http.StatusNotFound, "申请不存在"
+	}
+	return &application, http.StatusOK, ""
+}
+
+// GetApplicationRevisions 获取申请的修订历史（面试官）
+func GetApplicationRevisions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		application, code, message := loadSeasonApplication(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var revisions []models.ApplicationRevision
+		if err := db.Where("application_id = ?", application.ID).Order("version ASC").Find(&revisions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		authorIds := make([]uuid.UUID, 0, len(revisions))
+		for _, revision := range revisions {
+			authorIds = append(authorIds, revision.AuthorID)
+		}
+		authorNames := loadUserNames(db, authorIds)
+		// 匿名评审轮次中的面试者显示化名
+		loadSeasonBlindView(db, c, application.SeasonID).maskNames(db, authorNames)
+
+		// 与上一个版本比较，列出变化的字段
+		items := make([]gin.H, 0, len(revisions))
+		var previous *applicationSnapshot
+		for _, revision := range revisions {
+			snapshot := parseSnapshot(revision)
+			changed := snapshotFields
+			if previous != nil {
+				changed = previous.changedFields(snapshot)
+			}
+			items = append(items, gin.H{
+				"version":       revision.Version,
+				"authorId":      revision.AuthorID.String(),
+				"authorName":    template.HTMLEscapeString(authorNames[revision.AuthorID.String()]),
+				"changedFields": changed,
+				"createdAt":     revision.CreatedAt,
+			})
+			previous = &snapshot
+		}
+
+		// 最新版本在前
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// GetApplicationRevisionDiff 获取申请任意两个版本之间的字段级差异（面试官）
+// 未指定 to 时为最新版本，未指定 from 时为 to 的上一个版本
+func GetApplicationRevisionDiff(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		application, code, message := loadSeasonApplication(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var latest int
+		if err := db.Model(&models.ApplicationRevision{}).
+			Where("application_id = ?", application.ID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if latest == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该申请没有修订记录"})
+			return
+		}
+
+		toVersion := latest
+		if raw := c.Query("to"); raw != "" {
+			version, err := strconv.Atoi(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "to 参数校验失败"})
+				return
+			}
+			toVersion = version
+		}
+		fromVersion := toVersion - 1
+		if raw := c.Query("from"); raw != "" {
+			version, err := strconv.Atoi(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "from 参数校验失败"})
+				return
+			}
+			fromVersion = version
+		}
+
+		// from 为 0 时与空申请比较
+		var fromSnapshot, toSnapshot applicationSnapshot
+		if fromVersion > 0 {
+			var revision models.ApplicationRevision
+			if err := db.Where("application_id = ? AND version = ?", application.ID, fromVersion).First(&revision).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "版本不存在"})
+				return
+			}
+			fromSnapshot = parseSnapshot(revision)
+		}
+		var toRevision models.ApplicationRevision
+		if err := db.Where("application_id = ? AND version = ?", application.ID, toVersion).First(&toRevision).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "版本不存在"})
+			return
+		}
+		toSnapshot = parseSnapshot(toRevision)
+
+		fromValues := fromSnapshot.values()
+		toValues := toSnapshot.values()
+
+		// 匿名评审轮次中的面试者隐藏身份字段的内容，只保留字段发生了变化
+		var user models.User
+		if err := db.Where("uuid = ?", application.UserID).First(&user).Error; err == nil && loadSeasonBlindView(db, c, application.SeasonID).masked(user) {
+			for _, field := range blindFields {
+				fromValues[field] = ""
+				toValues[field] = ""
+			}
+		}
+		changes := make([]gin.H, 0)
+		for _, field := range fromSnapshot.changedFields(toSnapshot) {
+			change := gin.H{
+				"field": field,
+				"from":  template.HTMLEscapeString(fromValues[field]),
+				"to":    template.HTMLEscapeString(toValues[field]),
+			}
+			// 简历按行给出差异
+			if field == "resume" {
+				lines := utils.DiffLines(fromValues[field], toValues[field])
+				for i := range lines {
+					lines[i].Text = template.HTMLEscapeString(lines[i].Text)
+				}
+				change["lines"] = lines
+			}
+			changes = append(changes, change)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"from":    fromVersion,
+				"to":      toVersion,
+				"changes": changes,
+			},
+		})
+	}
+}
+
+// applicationWarnings 检查申请在最近一次状态变更后是否被修改
+func applicationWarnings(db *gorm.DB, application models.Application) []gin.H {
+	warnings := make([]gin.H, 0)
+
+	var lastChange models.StatusChange
+	if err := db.Where("user_id = ? AND season_id = ?", application.UserID, application.SeasonID).
+		Order("created_at DESC").First(&lastChange).Error; err != nil {
+		return warnings
+	}
+	if !application.UpdatedAt.After(lastChange.CreatedAt) {
+		return warnings
+	}
+
+	// 与状态变更时的版本比较，找出之后修改的字段
+	fields := []string{}
+	var baseline models.ApplicationRevision
+	if err := db.Where("application_id = ? AND created_at <= ?", application.ID, lastChange.CreatedAt).
+		Order("version DESC").First(&baseline).Error; err == nil {
+		current := applicationSnapshotOf(application)
+		current.Answers = answerSnapshot(loadApplicationAnswers(db, application.ID))
+		fields = parseSnapshot(baseline).changedFields(current)
+		if len(fields) == 0 {
+			return warnings
+		}
+	}
+
+	warnings = append(warnings, gin.H{
+		"type":            "modified_after_status_change",
+		"message":         "申请在最近一次状态变更后被修改",
+		"statusChangedAt": lastChange.CreatedAt,
+		"modifiedAt":      application.UpdatedAt,
+		"fields":          fields,
+	})
+	return warnings
+}
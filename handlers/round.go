@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultRounds 默认的面试轮次，数据库中没有配置时写入
+var defaultRounds = []models.Round{
+	{Number: 1, Name: "第一轮"},
+	{Number: 2, Name: "第二轮"},
+}
+
+// defaultStatusDefinitions 默认的面试状态，数据库中没有配置时写入
+var defaultStatusDefinitions = []models.StatusDefinition{
+	{Key: "rejected", Label: "未通过", SortOrder: 0, Terminal: true},
+	{Key: "r1_pending", Label: "第一轮待面试", Round: 1, SortOrder: 10, Initial: true},
+	{Key: "r1_passed", Label: "第一轮通过", Round: 1, SortOrder: 20},
+	{Key: "r2_pending", Label: "第二轮待面试", Round: 2, SortOrder: 30},
+	{Key: "r2_passed", Label: "第二轮通过", Round: 2, SortOrder: 40},
+	{Key: "offer", Label: "已录取", SortOrder: 50, Terminal: true},
+}
+
+// SeedStatusDefinitions 初始化默认的面试轮次与面试状态（仅在表为空时写入）
+func SeedStatusDefinitions(db *gorm.DB) {
+	var roundCount int64
+	if err := db.Model(&models.Round{}).Count(&roundCount).Error; err != nil {
+		log.Printf("读取面试轮次配置失败: %v", err)
+		return
+	}
+	if roundCount == 0 {
+		rounds := make([]models.Round, len(defaultRounds))
+		copy(rounds, defaultRounds)
+		if err := db.Create(&rounds).Error; err != nil {
+			log.Printf("写入默认面试轮次失败: %v", err)
+		}
+	}
+
+	var statusCount int64
+	if err := db.Model(&models.StatusDefinition{}).Count(&statusCount).Error; err != nil {
+		log.Printf("读取面试状态配置失败: %v", err)
+		return
+	}
+	if statusCount == 0 {
+		definitions := make([]models.StatusDefinition, len(defaultStatusDefinitions))
+		copy(definitions, defaultStatusDefinitions)
+		if err := db.Create(&definitions).Error; err != nil {
+			log.Printf("写入默认面试状态失败: %v", err)
+		}
+	}
+}
+
+// loadStatusDefinitions 按流程顺序查询所有面试状态
+func loadStatusDefinitions(db *gorm.DB) ([]models.StatusDefinition, error) {
+	var definitions []models.StatusDefinition
+	if err := db.Order("sort_order ASC, id ASC").Find(&definitions).Error; err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// findStatusDefinition 查询单个面试状态
+func findStatusDefinition(db *gorm.DB, key string) (*models.StatusDefinition, error) {
+	var definition models.StatusDefinition
+	if err := db.Where("status_key = ?", key).First(&definition).Error; err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// validateStatus 检查面试状态是否已在数据库中定义
+func validateStatus(db *gorm.DB, key string) bool {
+	if key == "" {
+		return false
+	}
+	var count int64
+	if err := db.Model(&models.StatusDefinition{}).Where("status_key = ?", key).Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// initialStatus 新注册用户与新申请方向的初始状态
+func initialStatus(db *gorm.DB) string {
+	var definition models.StatusDefinition
+	if err := db.Where("is_initial = ?", true).Order("sort_order ASC, id ASC").First(&definition).Error; err != nil {
+		return "r1_pending"
+	}
+	return definition.Key
+}
+
+// statusLabels 查询面试状态对面试者展示的名称
+func statusLabels(db *gorm.DB) map[string]string {
+	result := make(map[string]string)
+	definitions, err := loadStatusDefinitions(db)
+	if err != nil {
+		return result
+	}
+	for _, definition := range definitions {
+		result[definition.Key] = definition.Label
+	}
+	return result
+}
+
+// statusLabel 查询单个面试状态的展示名称，未定义时返回原始值
+func statusLabel(db *gorm.DB, key string) string {
+	definition, err := findStatusDefinition(db, key)
+	if err != nil {
+		return key
+	}
+	return definition.Label
+}
+
+// statusRanks 面试状态在流程中的先后，用于汇总各方向状态
+func statusRanks(db *gorm.DB) map[string]int {
+	result := make(map[string]int)
+	definitions, err := loadStatusDefinitions(db)
+	if err != nil {
+		return result
+	}
+	for _, definition := range definitions {
+		result[definition.Key] = definition.SortOrder
+	}
+	return result
+}
+
+// statusDefinitionData 构建面试状态的返回数据
+func statusDefinitionData(definition models.StatusDefinition) gin.H {
+	return gin.H{
+		"key":       definition.Key,
+		"label":     template.HTMLEscapeString(definition.Label),
+		"round":     definition.Round,
+		"sortOrder": definition.SortOrder,
+		"terminal":  definition.Terminal,
+		"initial":   definition.Initial,
+	}
+}
+
+// GetStatuses 获取面试轮次与面试状态定义（公开）
+func GetStatuses(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var rounds []models.Round
+		if err := db.Order("number ASC").Find(&rounds).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		definitions, err := loadStatusDefinitions(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		roundItems := make([]gin.H, 0, len(rounds))
+		for _, round := range rounds {
+			roundItems = append(roundItems, gin.H{
+				"number": round.Number,
+				"name":   template.HTMLEscapeString(round.Name),
+				"blind":  round.Blind,
+			})
+		}
+
+		statusItems := make([]gin.H, 0, len(definitions))
+		for _, definition := range definitions {
+			statusItems = append(statusItems, statusDefinitionData(definition))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"rounds":   roundItems,
+				"statuses": statusItems,
+			},
+		})
+	}
+}
+
+// StatusDefinitionRequest 创建/更新面试状态请求
+type StatusDefinitionRequest struct {
+	Key       string `json:"key" binding:"max=32"`
+	Label     string `json:"label" binding:"required,max=50"`
+	Round     int    `json:"round" binding:"min=0"`
+	SortOrder int    `json:"sortOrder"`
+	Terminal  bool   `json:"terminal"`
+	Initial   bool   `json:"initial"`
+}
+
+// validateStatusKey 状态标识只允许小写字母、数字与下划线
+func validateStatusKey(key string) bool {
+	if key == "" || len(key) > 32 {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// roundExists 检查轮次是否存在，0 表示不属于任何轮次
+func roundExists(db *gorm.DB, number int) bool {
+	if number == 0 {
+		return true
+	}
+	var count int64
+	if err := db.Model(&models.Round{}).Where("number = ?", number).Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// CreateStatus 新增面试状态（管理员）
+func CreateStatus(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req StatusDefinitionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !validateStatusKey(req.Key) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "状态标识只能包含小写字母、数字与下划线"})
+			return
+		}
+
+		if !roundExists(db, req.Round) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "面试轮次不存在"})
+			return
+		}
+
+		if validateStatus(db, req.Key) {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "状态标识已存在"})
+			return
+		}
+
+		definition := models.StatusDefinition{
+			Key:       req.Key,
+			Label:     req.Label,
+			Round:     req.Round,
+			SortOrder: req.SortOrder,
+			Terminal:  req.Terminal,
+			Initial:   req.Initial,
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// 初始状态只能有一个
+			if definition.Initial {
+				if err := tx.Model(&models.StatusDefinition{}).Where("is_initial = ?", true).Update("is_initial", false).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Create(&definition).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"key": definition.Key,
+			},
+		})
+	}
+}
+
+// UpdateStatus 更新面试状态（管理员），状态标识不可修改
+func UpdateStatus(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req StatusDefinitionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		definition, err := findStatusDefinition(db, c.Param("key"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试状态不存在"})
+			return
+		}
+
+		if !roundExists(db, req.Round) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "面试轮次不存在"})
+			return
+		}
+
+		// 不能取消唯一的初始状态
+		if definition.Initial && !req.Initial {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "请先将其他状态设为初始状态"})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if req.Initial && !definition.Initial {
+				if err := tx.Model(&models.StatusDefinition{}).Where("is_initial = ?", true).Update("is_initial", false).Error; err != nil {
+					return err
+				}
+			}
+			updates := map[string]interface{}{
+				"label":       req.Label,
+				"round":       req.Round,
+				"sort_order":  req.SortOrder,
+				"is_terminal": req.Terminal,
+				"is_initial":  req.Initial,
+			}
+			return tx.Model(definition).Updates(updates).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteStatus 删除面试状态（管理员），仍有面试者处于该状态时不可删除
+func DeleteStatus(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		definition, err := findStatusDefinition(db, c.Param("key"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试状态不存在"})
+			return
+		}
+
+		if definition.Initial {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "不能删除初始状态"})
+			return
+		}
+
+		// 检查是否仍在使用
+		var userCount, directionCount int64
+		if err := db.Model(&models.User{}).Where("status = ?", definition.Key).Count(&userCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if err := db.Model(&models.DirectionStatus{}).Where("status = ?", definition.Key).Count(&directionCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if userCount > 0 || directionCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "仍有面试者处于该状态，无法删除"})
+			return
+		}
+
+		// 同时删除相关的状态流转
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("from_status = ? OR to_status = ?", definition.Key, definition.Key).Delete(&models.StatusTransition{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(definition).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// RoundRequest 创建/更新面试轮次请求
+type RoundRequest struct {
+	Number int    `json:"number" binding:"min=1"`
+	Name   string `json:"name" binding:"required,max=50"`
+	Blind  bool   `json:"blind"`
+}
+
+// UpdateRoundRequest 修改面试轮次请求
+type UpdateRoundRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+	// Blind 不传时保持原有的匿名评审设置
+	Blind *bool `json:"blind"`
+}
+
+// CreateRound 新增面试轮次（管理员）
+func CreateRound(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RoundRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if roundExists(db, req.Number) {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "面试轮次已存在"})
+			return
+		}
+
+		round := models.Round{Number: req.Number, Name: req.Name, Blind: req.Blind}
+		if err := db.Create(&round).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"number": round.Number,
+			},
+		})
+	}
+}
+
+// UpdateRound 修改面试轮次名称与匿名评审设置（管理员）
+func UpdateRound(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req UpdateRoundRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var round models.Round
+		if err := db.Where("number = ?", number).First(&round).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试轮次不存在"})
+			return
+		}
+
+		updates := map[string]interface{}{"name": req.Name}
+		if req.Blind != nil {
+			updates["blind"] = *req.Blind
+		}
+		if err := db.Model(&round).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteRound 删除面试轮次（管理员），仍有状态属于该轮次时不可删除
+func DeleteRound(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var round models.Round
+		if err := db.Where("number = ?", number).First(&round).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试轮次不存在"})
+			return
+		}
+
+		var count int64
+		if err := db.Model(&models.StatusDefinition{}).Where("round = ?", number).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "仍有面试状态属于该轮次，无法删除"})
+			return
+		}
+
+		if err := db.Model(&models.Rubric{}).Where("round = ?", number).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "仍有评分标准属于该轮次，无法删除"})
+			return
+		}
+
+		if err := db.Delete(&round).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RubricCriterionItem 评分项
+type RubricCriterionItem struct {
+	// ID 修改评分标准时用于对应已有的评分项，新增的评分项留空
+	ID          string  `json:"id"`
+	Name        string  `json:"name" binding:"required,max=50"`
+	Description string  `json:"description" binding:"max=500"`
+	Weight      float64 `json:"weight" binding:"gt=0,lte=100"`
+}
+
+// RubricRequest 创建/修改评分标准请求
+type RubricRequest struct {
+	Direction string                `json:"direction" binding:"required"`
+	Round     int                   `json:"round" binding:"min=1"`
+	Name      string                `json:"name" binding:"required,max=50"`
+	ScaleMin  int                   `json:"scaleMin" binding:"min=0,max=100"`
+	ScaleMax  int                   `json:"scaleMax" binding:"min=1,max=100"`
+	Criteria  []RubricCriterionItem `json:"criteria" binding:"required,min=1,max=20,dive"`
+}
+
+// validate 校验评分标准参数
+func (r RubricRequest) validate(db *gorm.DB) string {
+	if !auth.ValidateDirections([]string{r.Direction}) {
+		return "方向参数校验失败"
+	}
+	if !roundExists(db, r.Round) {
+		return "面试轮次不存在"
+	}
+	if r.ScaleMax <= r.ScaleMin {
+		return "分值上限必须大于下限"
+	}
+	return ""
+}
+
+// loadRubricCriteria 批量查询评分项，按排序返回
+func loadRubricCriteria(db *gorm.DB, rubricIds []uuid.UUID) map[uuid.UUID][]models.RubricCriterion {
+	result := make(map[uuid.UUID][]models.RubricCriterion)
+	if len(rubricIds) == 0 {
+		return result
+	}
+	var criteria []models.RubricCriterion
+	db.Where("rubric_id IN ?", rubricIds).Order("sort_order ASC").Find(&criteria)
+	for _, criterion := range criteria {
+		result[criterion.RubricID] = append(result[criterion.RubricID], criterion)
+	}
+	return result
+}
+
+// rubricData 构建评分标准的返回数据
+func rubricData(rubric models.Rubric, criteria []models.RubricCriterion) gin.H {
+	items := make([]gin.H, 0, len(criteria))
+	for _, criterion := range criteria {
+		items = append(items, gin.H{
+			"id":          criterion.UUID.String(),
+			"name":        template.HTMLEscapeString(criterion.Name),
+			"description": template.HTMLEscapeString(criterion.Description),
+			"weight":      criterion.Weight,
+		})
+	}
+	return gin.H{
+		"id":        rubric.UUID.String(),
+		"direction": rubric.Direction,
+		"round":     rubric.Round,
+		"name":      template.HTMLEscapeString(rubric.Name),
+		"scaleMin":  rubric.ScaleMin,
+		"scaleMax":  rubric.ScaleMax,
+		"criteria":  items,
+		"createdAt": rubric.CreatedAt,
+		"updatedAt": rubric.UpdatedAt,
+	}
+}
+
+// rubricHasScorecards 评分标准是否已有评分表
+func rubricHasScorecards(db *gorm.DB, rubricUUID uuid.UUID) bool {
+	var count int64
+	db.Model(&models.Scorecard{}).Where("rubric_id = ?", rubricUUID).Count(&count)
+	return count > 0
+}
+
+// GetRubrics 获取评分标准（面试官）
+func GetRubrics(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		tx := db.Where("season_id = ?", season.UUID)
+		if directions := splitQueryList(c.QueryArray("direction")); len(directions) > 0 {
+			tx = tx.Where("direction IN ?", directions)
+		}
+		if round := c.Query("round"); round != "" {
+			tx = tx.Where("round = ?", round)
+		}
+
+		var rubrics []models.Rubric
+		if err := tx.Order("round ASC, direction ASC").Find(&rubrics).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		rubricIds := make([]uuid.UUID, 0, len(rubrics))
+		for _, rubric := range rubrics {
+			rubricIds = append(rubricIds, rubric.UUID)
+		}
+		criteria := loadRubricCriteria(db, rubricIds)
+
+		items := make([]gin.H, 0, len(rubrics))
+		for _, rubric := range rubrics {
+			items = append(items, rubricData(rubric, criteria[rubric.UUID]))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// CreateRubric 为当前招新季的某个方向和轮次创建评分标准（管理员）
+func CreateRubric(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RubricRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		if message := req.validate(db); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var count int64
+		db.Model(&models.Rubric{}).Where("season_id = ? AND direction = ? AND round = ?", seasonID, req.Direction, req.Round).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "该方向和轮次已有评分标准"})
+			return
+		}
+
+		rubricUUID, _ := uuid.NewUUID()
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&models.Rubric{
+				UUID:      rubricUUID,
+				SeasonID:  seasonID,
+				Direction: req.Direction,
+				Round:     req.Round,
+				Name:      req.Name,
+				ScaleMin:  req.ScaleMin,
+				ScaleMax:  req.ScaleMax,
+			}).Error; err != nil {
+				return err
+			}
+			for i, item := range req.Criteria {
+				criterionUUID, _ := uuid.NewUUID()
+				if err := tx.Create(&models.RubricCriterion{
+					UUID:        criterionUUID,
+					RubricID:    rubricUUID,
+					Name:        item.Name,
+					Description: item.Description,
+					Weight:      item.Weight,
+					SortOrder:   i,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": rubricUUID.String(),
+			},
+		})
+	}
+}
+
+// UpdateRubric 修改评分标准（管理员）
+// 已有评分表时只能修改名称、评分项名称和说明，不能修改方向、轮次、分值范围、权重或增删评分项
+func UpdateRubric(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rubricUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req RubricRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		if message := req.validate(db); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var rubric models.Rubric
+		if err := db.Where("uuid = ?", rubricUUID).First(&rubric).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "评分标准不存在"})
+			return
+		}
+
+		if req.Direction != rubric.Direction || req.Round != rubric.Round {
+			var count int64
+			db.Model(&models.Rubric{}).Where("season_id = ? AND direction = ? AND round = ? AND uuid <> ?", rubric.SeasonID, req.Direction, req.Round, rubric.UUID).Count(&count)
+			if count > 0 {
+				c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "该方向和轮次已有评分标准"})
+				return
+			}
+		}
+
+		seen := make(map[string]bool, len(req.Criteria))
+		for _, item := range req.Criteria {
+			if item.ID != "" && seen[item.ID] {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "评分项重复"})
+				return
+			}
+			seen[item.ID] = true
+		}
+
+		existing := loadRubricCriteria(db, []uuid.UUID{rubric.UUID})[rubric.UUID]
+		existingById := make(map[string]models.RubricCriterion, len(existing))
+		for _, criterion := range existing {
+			existingById[criterion.UUID.String()] = criterion
+		}
+
+		if rubricHasScorecards(db, rubric.UUID) {
+			structural := req.Direction != rubric.Direction || req.Round != rubric.Round ||
+				req.ScaleMin != rubric.ScaleMin || req.ScaleMax != rubric.ScaleMax ||
+				len(req.Criteria) != len(existing)
+			for _, item := range req.Criteria {
+				criterion, found := existingById[item.ID]
+				if !found || criterion.Weight != item.Weight {
+					structural = true
+				}
+			}
+			if structural {
+				c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "已有评分表，只能修改名称和说明"})
+				return
+			}
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			updates := map[string]interface{}{
+				"direction": req.Direction,
+				"round":     req.Round,
+				"name":      req.Name,
+				"scale_min": req.ScaleMin,
+				"scale_max": req.ScaleMax,
+			}
+			if err := tx.Model(&rubric).Updates(updates).Error; err != nil {
+				return err
+			}
+
+			kept := make([]uuid.UUID, 0, len(req.Criteria))
+			for i, item := range req.Criteria {
+				if criterion, found := existingById[item.ID]; found {
+					kept = append(kept, criterion.UUID)
+					if err := tx.Model(&criterion).Updates(map[string]interface{}{
+						"name":        item.Name,
+						"description": item.Description,
+						"weight":      item.Weight,
+						"sort_order":  i,
+					}).Error; err != nil {
+						return err
+					}
+					continue
+				}
+				criterionUUID, _ := uuid.NewUUID()
+				kept = append(kept, criterionUUID)
+				if err := tx.Create(&models.RubricCriterion{
+					UUID:        criterionUUID,
+					RubricID:    rubric.UUID,
+					Name:        item.Name,
+					Description: item.Description,
+					Weight:      item.Weight,
+					SortOrder:   i,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Where("rubric_id = ? AND uuid NOT IN ?", rubric.UUID, kept).Delete(&models.RubricCriterion{}).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteRubric 删除评分标准（管理员），已有评分表时不可删除
+func DeleteRubric(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rubricUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var rubric models.Rubric
+		if err := db.Where("uuid = ?", rubricUUID).First(&rubric).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "评分标准不存在"})
+			return
+		}
+
+		if rubricHasScorecards(db, rubric.UUID) {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "已有评分表，无法删除"})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("rubric_id = ?", rubric.UUID).Delete(&models.RubricCriterion{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&rubric).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
@@ -0,0 +1,458 @@
+package handlers
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// weightedTotal 按权重计算加权平均分，结果仍在评分标准的分值范围内，保留两位小数
+func weightedTotal(criteria []models.RubricCriterion, scores map[uuid.UUID]int) float64 {
+	var sum, weights float64
+	for _, criterion := range criteria {
+		sum += float64(scores[criterion.UUID]) * criterion.Weight
+		weights += criterion.Weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return math.Round(sum/weights*100) / 100
+}
+
+// scoreStats 计算一组分数的统计量
+func scoreStats(values []float64) gin.H {
+	if len(values) == 0 {
+		return gin.H{"count": 0, "mean": 0, "median": 0, "min": 0, "max": 0, "stddev": 0}
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	mean, stddev := meanStddev(sorted)
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return gin.H{
+		"count":  len(sorted),
+		"mean":   math.Round(mean*100) / 100,
+		"median": math.Round(median*100) / 100,
+		"min":    sorted[0],
+		"max":    sorted[len(sorted)-1],
+		"stddev": math.Round(stddev*100) / 100,
+	}
+}
+
+// meanStddev 计算平均值与总体标准差
+func meanStddev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// scoreHistogram 按整数分值统计分布，total 向下取整
+func scoreHistogram(values []float64, scaleMin, scaleMax int) []gin.H {
+	counts := make([]int, scaleMax-scaleMin+1)
+	for _, value := range values {
+		bucket := int(math.Floor(value)) - scaleMin
+		if bucket >= 0 && bucket < len(counts) {
+			counts[bucket]++
+		}
+	}
+	buckets := make([]gin.H, 0, len(counts))
+	for i, count := range counts {
+		buckets = append(buckets, gin.H{"score": scaleMin + i, "count": count})
+	}
+	return buckets
+}
+
+// loadScoreItems 批量查询评分表的各项分数
+func loadScoreItems(db *gorm.DB, scorecardIds []uuid.UUID) map[uuid.UUID]map[uuid.UUID]int {
+	result := make(map[uuid.UUID]map[uuid.UUID]int)
+	if len(scorecardIds) == 0 {
+		return result
+	}
+	var items []models.ScoreItem
+	db.Where("scorecard_id IN ?", scorecardIds).Find(&items)
+	for _, item := range items {
+		if result[item.ScorecardID] == nil {
+			result[item.ScorecardID] = make(map[uuid.UUID]int)
+		}
+		result[item.ScorecardID][item.CriterionID] = item.Score
+	}
+	return result
+}
+
+// deleteScorecards 在事务中删除符合条件的评分表及其分数
+func deleteScorecards(tx *gorm.DB, query string, args ...interface{}) error {
+	var scorecardIds []uuid.UUID
+	if err := tx.Model(&models.Scorecard{}).Where(query, args...).Pluck("uuid", &scorecardIds).Error; err != nil {
+		return err
+	}
+	if len(scorecardIds) == 0 {
+		return nil
+	}
+	if err := tx.Where("scorecard_id IN ?", scorecardIds).Delete(&models.ScoreItem{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("uuid IN ?", scorecardIds).Delete(&models.Scorecard{}).Error
+}
+
+// ScoreItemRequest 单项评分
+type ScoreItemRequest struct {
+	CriterionID string `json:"criterionId" binding:"required"`
+	Score       int    `json:"score"`
+}
+
+// SubmitScorecardRequest 提交评分表请求
+type SubmitScorecardRequest struct {
+	UserID    string             `json:"userId" binding:"required"`
+	Direction string             `json:"direction" binding:"required"`
+	Round     int                `json:"round" binding:"min=1"`
+	Scores    []ScoreItemRequest `json:"scores" binding:"required,min=1,dive"`
+	Comment   string             `json:"comment" binding:"max=1000"`
+}
+
+// SubmitScorecard 提交或修改自己对面试者某一轮的评分表（面试官）
+// 每个面试官对同一面试者的同一方向和轮次只有一份评分表，必须为评分标准中的每一项打分
+func SubmitScorecard(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SubmitScorecardRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		interviewerUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		userUUID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if hasConflict(db, interviewerUUID, userUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": conflictMessage})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var directionStatus models.DirectionStatus
+		if err := db.Where("user_id = ? AND season_id = ? AND direction = ?", userUUID, seasonID, req.Direction).First(&directionStatus).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试者没有申请该方向"})
+			return
+		}
+
+		var rubric models.Rubric
+		if err := db.Where("season_id = ? AND direction = ? AND round = ?", seasonID, req.Direction, req.Round).First(&rubric).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该方向和轮次没有评分标准"})
+			return
+		}
+		criteria := loadRubricCriteria(db, []uuid.UUID{rubric.UUID})[rubric.UUID]
+
+		// 每个评分项必须且只能打一次分
+		scores := make(map[uuid.UUID]int, len(req.Scores))
+		for _, item := range req.Scores {
+			criterionUUID, err := uuid.Parse(item.CriterionID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+				return
+			}
+			if _, duplicated := scores[criterionUUID]; duplicated {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "评分项重复"})
+				return
+			}
+			if item.Score < rubric.ScaleMin || item.Score > rubric.ScaleMax {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "分数超出范围 " + strconv.Itoa(rubric.ScaleMin) + "-" + strconv.Itoa(rubric.ScaleMax)})
+				return
+			}
+			scores[criterionUUID] = item.Score
+		}
+		if len(scores) != len(criteria) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "请为每个评分项打分"})
+			return
+		}
+		for _, criterion := range criteria {
+			if _, scored := scores[criterion.UUID]; !scored {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "请为每个评分项打分"})
+				return
+			}
+		}
+		total := weightedTotal(criteria, scores)
+
+		// 同一面试官对同一面试者只有一份评分表，并发提交时由唯一索引合并为更新
+		scorecardUUID, _ := uuid.NewUUID()
+		scorecard := models.Scorecard{
+			UUID:          scorecardUUID,
+			RubricID:      rubric.UUID,
+			UserID:        userUUID,
+			InterviewerID: interviewerUUID,
+			SeasonID:      seasonID,
+			Direction:     rubric.Direction,
+			Round:         rubric.Round,
+			Total:         total,
+			Comment:       req.Comment,
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "rubric_id"}, {Name: "user_id"}, {Name: "interviewer_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"total", "comment", "updated_at"}),
+			}).Create(&scorecard).Error
+			if err != nil {
+				return err
+			}
+			// 已有评分表时主键仍为原来的记录
+			if err := tx.Where("rubric_id = ? AND user_id = ? AND interviewer_id = ?", rubric.UUID, userUUID, interviewerUUID).First(&scorecard).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("scorecard_id = ?", scorecard.UUID).Delete(&models.ScoreItem{}).Error; err != nil {
+				return err
+			}
+			for _, criterion := range criteria {
+				if err := tx.Create(&models.ScoreItem{ScorecardID: scorecard.UUID, CriterionID: criterion.UUID, Score: scores[criterion.UUID]}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id":    scorecard.UUID.String(),
+				"total": total,
+			},
+		})
+	}
+}
+
+// GetScorecards 获取评分表（面试官）
+func GetScorecards(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		tx := db.Where("season_id = ?", season.UUID)
+		if candidateID := c.Query("userId"); candidateID != "" {
+			candidateUUID, err := uuid.Parse(candidateID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+				return
+			}
+			tx = tx.Where("user_id = ?", candidateUUID)
+		}
+		if interviewerID := c.Query("interviewerId"); interviewerID != "" {
+			interviewerUUID, err := uuid.Parse(interviewerID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+				return
+			}
+			tx = tx.Where("interviewer_id = ?", interviewerUUID)
+		}
+		if directions := splitQueryList(c.QueryArray("direction")); len(directions) > 0 {
+			tx = tx.Where("direction IN ?", directions)
+		}
+		if round := c.Query("round"); round != "" {
+			tx = tx.Where("round = ?", round)
+		}
+
+		var scorecards []models.Scorecard
+		if err := tx.Order("created_at ASC").Find(&scorecards).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		scorecardIds := make([]uuid.UUID, 0, len(scorecards))
+		userIds := make([]uuid.UUID, 0, len(scorecards)*2)
+		for _, scorecard := range scorecards {
+			scorecardIds = append(scorecardIds, scorecard.UUID)
+			userIds = append(userIds, scorecard.UserID, scorecard.InterviewerID)
+		}
+		scoreItems := loadScoreItems(db, scorecardIds)
+		userNames := loadUserNames(db, userIds)
+		// 匿名评审轮次中的面试者显示化名
+		loadSeasonBlindView(db, c, season.UUID).maskNames(db, userNames)
+		rubricIds := make([]uuid.UUID, 0, len(scorecards))
+		for _, scorecard := range scorecards {
+			if !slices.Contains(rubricIds, scorecard.RubricID) {
+				rubricIds = append(rubricIds, scorecard.RubricID)
+			}
+		}
+		criteria := loadRubricCriteria(db, rubricIds)
+
+		items := make([]gin.H, 0, len(scorecards))
+		for _, scorecard := range scorecards {
+			// 按评分项的顺序输出
+			scores := make([]gin.H, 0, len(scoreItems[scorecard.UUID]))
+			for _, criterion := range criteria[scorecard.RubricID] {
+				if score, scored := scoreItems[scorecard.UUID][criterion.UUID]; scored {
+					scores = append(scores, gin.H{"criterionId": criterion.UUID.String(), "score": score})
+				}
+			}
+			items = append(items, gin.H{
+				"id":              scorecard.UUID.String(),
+				"rubricId":        scorecard.RubricID.String(),
+				"userId":          scorecard.UserID.String(),
+				"userName":        template.HTMLEscapeString(userNames[scorecard.UserID.String()]),
+				"interviewerId":   scorecard.InterviewerID.String(),
+				"interviewerName": template.HTMLEscapeString(userNames[scorecard.InterviewerID.String()]),
+				"direction":       scorecard.Direction,
+				"round":           scorecard.Round,
+				"total":           scorecard.Total,
+				"scores":          scores,
+				"comment":         template.HTMLEscapeString(scorecard.Comment),
+				"createdAt":       scorecard.CreatedAt,
+				"updatedAt":       scorecard.UpdatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// DeleteScorecard 删除评分表（评分的面试官或管理员）
+func DeleteScorecard(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scorecardUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var scorecard models.Scorecard
+		if err := db.Where("uuid = ?", scorecardUUID).First(&scorecard).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "评分表不存在"})
+			return
+		}
+
+		if scorecard.InterviewerID != userUUID && !IsAdmin(db, userUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "只能删除自己的评分表"})
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return deleteScorecards(tx, "uuid = ?", scorecard.UUID)
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// GetScoreDistribution 获取某个方向和轮次的分数分布（面试官）
+// 包含所有评分表加权总分的统计量与分布，以及每个评分项的统计量与分布
+func GetScoreDistribution(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		round, err := strconv.Atoi(c.Query("round"))
+		if err != nil || c.Query("direction") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var rubric models.Rubric
+		if err := db.Where("season_id = ? AND direction = ? AND round = ?", season.UUID, c.Query("direction"), round).First(&rubric).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该方向和轮次没有评分标准"})
+			return
+		}
+		criteria := loadRubricCriteria(db, []uuid.UUID{rubric.UUID})[rubric.UUID]
+
+		var scorecards []models.Scorecard
+		if err := db.Where("rubric_id = ?", rubric.UUID).Find(&scorecards).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		totals := make([]float64, 0, len(scorecards))
+		scorecardIds := make([]uuid.UUID, 0, len(scorecards))
+		candidates := make(map[uuid.UUID]struct{})
+		for _, scorecard := range scorecards {
+			totals = append(totals, scorecard.Total)
+			scorecardIds = append(scorecardIds, scorecard.UUID)
+			candidates[scorecard.UserID] = struct{}{}
+		}
+		scoreItems := loadScoreItems(db, scorecardIds)
+
+		criterionItems := make([]gin.H, 0, len(criteria))
+		for _, criterion := range criteria {
+			values := make([]float64, 0, len(scorecards))
+			for _, scorecard := range scorecards {
+				if score, scored := scoreItems[scorecard.UUID][criterion.UUID]; scored {
+					values = append(values, float64(score))
+				}
+			}
+			criterionItems = append(criterionItems, gin.H{
+				"id":        criterion.UUID.String(),
+				"name":      template.HTMLEscapeString(criterion.Name),
+				"weight":    criterion.Weight,
+				"stats":     scoreStats(values),
+				"histogram": scoreHistogram(values, rubric.ScaleMin, rubric.ScaleMax),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"rubric":     rubricData(rubric, criteria),
+				"candidates": len(candidates),
+				"total": gin.H{
+					"stats":     scoreStats(totals),
+					"histogram": scoreHistogram(totals, rubric.ScaleMin, rubric.ScaleMax),
+				},
+				"criteria": criterionItems,
+			},
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// seasonScopedTables 按招新季划分的数据表
+var seasonScopedTables = []interface{}{
+	&models.Application{},
+	&models.DirectionStatus{},
+	&models.StatusChange{},
+	&models.Task{},
+	&models.Announcement{},
+}
+
+// SeedSeasons 初始化招新季：没有招新季时创建默认招新季，并将未归属招新季的旧数据归入当前招新季
+func SeedSeasons(db *gorm.DB) {
+	// 旧的唯一索引不包含招新季，会阻止往届成员重新申请
+	if db.Migrator().HasIndex(&models.Application{}, "idx_applications_user_id") {
+		if err := db.Migrator().DropIndex(&models.Application{}, "idx_applications_user_id"); err != nil {
+			log.Printf("删除旧的申请索引失败: %v", err)
+		}
+	}
+	if db.Migrator().HasIndex(&models.DirectionStatus{}, "idx_user_direction") {
+		if err := db.Migrator().DropIndex(&models.DirectionStatus{}, "idx_user_direction"); err != nil {
+			log.Printf("删除旧的方向状态索引失败: %v", err)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&models.Season{}).Count(&count).Error; err != nil {
+		log.Printf("读取招新季失败: %v", err)
+		return
+	}
+	if count == 0 {
+		now := time.Now()
+		seasonUUID, _ := uuid.NewUUID()
+		season := models.Season{
+			UUID:     seasonUUID,
+			Name:     strconv.Itoa(now.Year()),
+			OpensAt:  now,
+			ClosesAt: now.AddDate(1, 0, 0),
+			Active:   true,
+		}
+		if err := db.Create(&season).Error; err != nil {
+			log.Printf("创建默认招新季失败: %v", err)
+			return
+		}
+	}
+
+	season, err := currentSeason(db)
+	if err != nil {
+		log.Printf("读取当前招新季失败: %v", err)
+		return
+	}
+	for _, table := range seasonScopedTables {
+		if err := db.Model(table).Where("season_id IS NULL OR season_id = ''").Update("season_id", season.UUID).Error; err != nil {
+			log.Printf("补齐招新季失败: %v", err)
+		}
+	}
+}
+
+// currentSeason 查询当前招新季，没有启用的招新季时取最近开放的一个
+func currentSeason(db *gorm.DB) (*models.Season, error) {
+	var season models.Season
+	if err := db.Where("active = ?", true).Order("opens_at DESC").First(&season).Error; err == nil {
+		return &season, nil
+	}
+	if err := db.Order("opens_at DESC").First(&season).Error; err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// currentSeasonID 查询当前招新季的ID
+func currentSeasonID(db *gorm.DB) (uuid.UUID, error) {
+	season, err := currentSeason(db)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return season.UUID, nil
+}
+
+// resolveSeason 解析请求中的 season 参数，未指定时使用当前招新季
+func resolveSeason(db *gorm.DB, c *gin.Context) (*models.Season, int, string) {
+	seasonID := c.Query("season")
+	if seasonID == "" {
+		season, err := currentSeason(db)
+		if err != nil {
+			return nil, http.StatusInternalServerError, "服务器错误"
+		}
+		return season, http.StatusOK, ""
+	}
+
+	seasonUUID, err := uuid.Parse(seasonID)
+	if err != nil {
+		return nil, http.StatusBadRequest, "season 参数校验失败"
+	}
+
+	var season models.Season
+	if err := db.Where("uuid = ?", seasonUUID).First(&season).Error; err != nil {
+		return nil, http.StatusNotFound, "招新季不存在"
+	}
+	return &season, http.StatusOK, ""
+}
+
+// seasonData 构建招新季的返回数据
+func seasonData(season models.Season) gin.H {
+	return gin.H{
+		"id":        season.UUID.String(),
+		"name":      template.HTMLEscapeString(season.Name),
+		"opensAt":   season.OpensAt,
+		"closesAt":  season.ClosesAt,
+		"active":    season.Active,
+		"createdAt": season.CreatedAt,
+	}
+}
+
+// GetSeasons 获取招新季列表（公开）
+func GetSeasons(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var seasons []models.Season
+		if err := db.Order("opens_at DESC").Find(&seasons).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		items := make([]gin.H, 0, len(seasons))
+		for _, season := range seasons {
+			items = append(items, seasonData(season))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// SeasonRequest 创建/更新招新季请求
+type SeasonRequest struct {
+	Name     string    `json:"name" binding:"required,max=50"`
+	OpensAt  time.Time `json:"opensAt" binding:"required"`
+	ClosesAt time.Time `json:"closesAt" binding:"required"`
+}
+
+// CreateSeason 创建招新季（管理员），新建的招新季需要单独启用
+func CreateSeason(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SeasonRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !req.ClosesAt.After(req.OpensAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "截止时间必须晚于开放时间"})
+			return
+		}
+
+		var count int64
+		db.Model(&models.Season{}).Where("name = ?", req.Name).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "招新季名称已存在"})
+			return
+		}
+
+		seasonUUID, _ := uuid.NewUUID()
+		season := models.Season{
+			UUID:     seasonUUID,
+			Name:     req.Name,
+			OpensAt:  req.OpensAt,
+			ClosesAt: req.ClosesAt,
+			Active:   false,
+		}
+		if err := db.Create(&season).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": seasonUUID.String(),
+			},
+		})
+	}
+}
+
+// UpdateSeason 修改招新季（管理员）
+func UpdateSeason(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req SeasonRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !req.ClosesAt.After(req.OpensAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "截止时间必须晚于开放时间"})
+			return
+		}
+
+		var season models.Season
+		if err := db.Where("uuid = ?", seasonUUID).First(&season).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "招新季不存在"})
+			return
+		}
+
+		var count int64
+		db.Model(&models.Season{}).Where("name = ? AND uuid <> ?", req.Name, seasonUUID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "招新季名称已存在"})
+			return
+		}
+
+		updates := map[string]interface{}{
+			"name":      req.Name,
+			"opens_at":  req.OpensAt,
+			"closes_at": req.ClosesAt,
+		}
+		if err := db.Model(&season).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// ActivateSeason 将招新季设为当前招新季（管理员）
+// 切换后面试者的方向与汇总状态按新招新季的申请重新计算，往届的申请与状态记录保留
+func ActivateSeason(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var season models.Season
+		if err := db.Where("uuid = ?", seasonUUID).First(&season).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "招新季不存在"})
+			return
+		}
+
+		if season.Active {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "该招新季已是当前招新季"})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Season{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&season).Update("active", true).Error; err != nil {
+				return err
+			}
+			return resetSeasonUsers(tx, season.UUID)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// resetSeasonUsers 切换招新季后重新计算面试者的方向与汇总状态
+func resetSeasonUsers(tx *gorm.DB, seasonUUID uuid.UUID) error {
+	applied := tx.Model(&models.Application{}).Select("user_id").Where("season_id = ?", seasonUUID)
+
+	// 本季没有申请的面试者回到初始状态
+	updates := map[string]interface{}{
+		"directions":           "[]",
+		"passed_directions":    "[]",
+		"passed_directions_by": "[]",
+		"status":               initialStatus(tx),
+	}
+	if err := tx.Model(&models.User{}).Where("role = ? AND uuid NOT IN (?)", "interviewee", applied).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// 本季已有申请的面试者按本季记录恢复
+	var applications []models.Application
+	if err := tx.Where("season_id = ?", seasonUUID).Find(&applications).Error; err != nil {
+		return err
+	}
+	for _, application := range applications {
+		if err := tx.Model(&models.User{}).Where("uuid = ?", application.UserID).Update("directions", application.Directions).Error; err != nil {
+			return err
+		}
+		if err := syncDirectionStatuses(tx, application.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadApplicationSeasons 查询用户提交过申请的所有招新季
+func loadApplicationSeasons(db *gorm.DB, userUUID uuid.UUID) []gin.H {
+	var seasons []models.Season
+	applied := db.Model(&models.Application{}).Select("season_id").Where("user_id = ?", userUUID)
+	if err := db.Where("uuid IN (?)", applied).Order("opens_at DESC").Find(&seasons).Error; err != nil {
+		return []gin.H{}
+	}
+
+	items := make([]gin.H, 0, len(seasons))
+	for _, season := range seasons {
+		items = append(items, seasonData(season))
+	}
+	return items
+}
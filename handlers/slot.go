@@ -0,0 +1,850 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bookingCutoff 面试开始前多久停止预约
+var bookingCutoff = 24 * time.Hour
+
+// cancelCutoff 面试开始前多久停止取消与改约
+var cancelCutoff = 12 * time.Hour
+
+// SetBookingCutoffs 设置预约与取消的截止时间（面试开始前的时长）
+func SetBookingCutoffs(book, cancel time.Duration) {
+	bookingCutoff = book
+	cancelCutoff = cancel
+}
+
+// errBookingRejected 预约未通过检查
+var errBookingRejected = errors.New("booking rejected")
+
+// slotData 构建面试时间段的返回数据，线上链接只对面试官与已预约的面试者可见
+func slotData(slot models.InterviewSlot, interviewerName string, includeLink bool) gin.H {
+	data := gin.H{
+		"id":               slot.UUID.String(),
+		"direction":        slot.Direction,
+		"startsAt":         slot.StartsAt,
+		"endsAt":           slot.StartsAt.Add(time.Duration(slot.Duration) * time.Minute),
+		"duration":         slot.Duration,
+		"location":         template.HTMLEscapeString(slot.Location),
+		"capacity":         slot.Capacity,
+		"bookedCount":      slot.BookedCount,
+		"remaining":        max(slot.Capacity-slot.BookedCount, 0),
+		"eligibleStatuses": parseJSONList(slot.EligibleStatuses),
+		"note":             template.HTMLEscapeString(slot.Note),
+		"interviewerId":    slot.InterviewerID.String(),
+		"interviewerName":  template.HTMLEscapeString(interviewerName),
+		"cancelled":        slot.CancelledAt != nil,
+	}
+	if includeLink {
+		data["onlineLink"] = template.HTMLEscapeString(slot.OnlineLink)
+	}
+	return data
+}
+
+// loadSlot 按路径参数查询面试时间段
+func loadSlot(db *gorm.DB, id string) (*models.InterviewSlot, int, string) {
+	slotUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, http.StatusBadRequest, "参数校验失败"
+	}
+	var slot models.InterviewSlot
+	if err := db.Where("uuid = ?", slotUUID).First(&slot).Error; err != nil {
+		return nil, http.StatusNotFound, "时间段不存在"
+	}
+	return &slot, http.StatusOK, ""
+}
+
+// canManageSlot 时间段只能由发布者或管理员修改
+func canManageSlot(db *gorm.DB, c *gin.Context, slot models.InterviewSlot) bool {
+	userUUID, ok := GetCurrentUserUUID(c)
+	if !ok {
+		return false
+	}
+	return slot.InterviewerID == userUUID || IsAdmin(db, userUUID)
+}
+
+// slotEligible 检查面试者在该方向的状态是否可以预约该时间段
+// 时间段未指定可预约状态时，该方向处于非终态即可预约
+func slotEligible(tx *gorm.DB, slot models.InterviewSlot, userUUID uuid.UUID) (bool, string) {
+	var directionStatus models.DirectionStatus
+	if err := tx.Where("user_id = ? AND season_id = ? AND direction = ?", userUUID, slot.SeasonID, slot.Direction).First(&directionStatus).Error; err != nil {
+		return false, "你没有申请该方向"
+	}
+	eligible := parseJSONList(slot.EligibleStatuses)
+	if len(eligible) == 0 {
+		if terminalStatuses(tx)[directionStatus.Status] {
+			return false, "当前面试状态不能预约"
+		}
+		return true, ""
+	}
+	if !slices.Contains(eligible, directionStatus.Status) {
+		return false, "当前面试状态不能预约该时间段"
+	}
+	return true, ""
+}
+
+// lockBookingUser 锁定面试者行，使同一面试者的预约、取消与改约串行执行
+// 各路径都先锁用户再锁时间段，避免加锁顺序不一致导致死锁
+func lockBookingUser(tx *gorm.DB, userUUID uuid.UUID) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("uuid").Where("uuid = ?", userUUID).First(&models.User{}).Error
+}
+
+// reserveSlot 在事务中为面试者预约时间段，slot 会被替换为锁定后重新读取的记录
+// 锁定用户行使同一面试者的预约串行执行，名额通过带条件的自增抢占，避免并发超额预约
+func reserveSlot(tx *gorm.DB, slot *models.InterviewSlot, userUUID uuid.UUID, now time.Time) (*models.SlotBooking, int, string, error) {
+	if err := lockBookingUser(tx, userUUID); err != nil {
+		return nil, http.StatusInternalServerError, "服务器错误", err
+	}
+	// 重新读取时间段，避免按修改或取消前的时间与状态检查
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", slot.UUID).First(slot).Error; err != nil {
+		return nil, http.StatusNotFound, "时间段不存在", errBookingRejected
+	}
+
+	if slot.CancelledAt != nil {
+		return nil, http.StatusBadRequest, "时间段已取消", errBookingRejected
+	}
+	seasonID, err := currentSeasonID(tx)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "服务器错误", err
+	}
+	if slot.SeasonID != seasonID {
+		return nil, http.StatusBadRequest, "时间段不属于当前招新季", errBookingRejected
+	}
+	if now.Add(bookingCutoff).After(slot.StartsAt) {
+		return nil, http.StatusBadRequest, "已超过预约截止时间", errBookingRejected
+	}
+	if ok, message := slotEligible(tx, *slot, userUUID); !ok {
+		return nil, http.StatusForbidden, message, errBookingRejected
+	}
+
+	var bookings []models.SlotBooking
+	if err := tx.Where("user_id = ? AND season_id = ? AND status = ?", userUUID, slot.SeasonID, "booked").Find(&bookings).Error; err != nil {
+		return nil, http.StatusInternalServerError, "服务器错误", err
+	}
+	if slices.ContainsFunc(bookings, func(booking models.SlotBooking) bool { return booking.Direction == slot.Direction }) {
+		return nil, http.StatusConflict, "你已预约该方向的面试，请先取消或改约", errBookingRejected
+	}
+
+	// 不同方向的面试时间不能重叠
+	if len(bookings) > 0 {
+		slotIds := make([]uuid.UUID, 0, len(bookings))
+		for _, booking := range bookings {
+			slotIds = append(slotIds, booking.SlotID)
+		}
+		var booked []models.InterviewSlot
+		if err := tx.Where("uuid IN ? AND cancelled_at IS NULL", slotIds).Find(&booked).Error; err != nil {
+			return nil, http.StatusInternalServerError, "服务器错误", err
+		}
+		endsAt := slot.StartsAt.Add(time.Duration(slot.Duration) * time.Minute)
+		for _, other := range booked {
+			otherEndsAt := other.StartsAt.Add(time.Duration(other.Duration) * time.Minute)
+			if other.StartsAt.Before(endsAt) && slot.StartsAt.Before(otherEndsAt) {
+				return nil, http.StatusConflict, "与你已预约的 " + other.Direction + " 方向面试时间冲突", errBookingRejected
+			}
+		}
+	}
+
+	result := tx.Model(&models.InterviewSlot{}).
+		Where("uuid = ? AND cancelled_at IS NULL AND booked_count < capacity", slot.UUID).
+		Update("booked_count", gorm.Expr("booked_count + 1"))
+	if result.Error != nil {
+		return nil, http.StatusInternalServerError, "服务器错误", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, http.StatusConflict, "该时间段已约满", errBookingRejected
+	}
+
+	bookingUUID, _ := uuid.NewUUID()
+	booking := models.SlotBooking{
+		UUID:      bookingUUID,
+		SlotID:    slot.UUID,
+		UserID:    userUUID,
+		SeasonID:  slot.SeasonID,
+		Direction: slot.Direction,
+		Status:    "booked",
+	}
+	if err := tx.Create(&booking).Error; err != nil {
+		return nil, http.StatusInternalServerError, "服务器错误", err
+	}
+	return &booking, http.StatusOK, "", nil
+}
+
+// releaseBooking 取消预约并释放名额
+func releaseBooking(tx *gorm.DB, booking models.SlotBooking, now time.Time) error {
+	result := tx.Model(&models.SlotBooking{}).
+		Where("uuid = ? AND status = ?", booking.UUID, "booked").
+		Updates(map[string]interface{}{"status": "cancelled", "cancelled_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	return tx.Model(&models.InterviewSlot{}).
+		Where("uuid = ? AND booked_count > 0", booking.SlotID).
+		Update("booked_count", gorm.Expr("booked_count - 1")).Error
+}
+
+// loadActiveBooking 查询面试者在该时间段的有效预约
+func loadActiveBooking(db *gorm.DB, slotUUID, userUUID uuid.UUID) (*models.SlotBooking, error) {
+	var booking models.SlotBooking
+	if err := db.Where("slot_id = ? AND user_id = ? AND status = ?", slotUUID, userUUID, "booked").First(&booking).Error; err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetSlots 获取面试时间段
+// 面试官可以看到所有时间段；面试者只能看到自己申请方向中未开始、未取消的时间段
+func GetSlots(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		isInterviewer := GetCurrentUserRole(c) == "interviewer"
+		tx := db.Where("season_id = ?", season.UUID)
+		if directions := splitQueryList(c.QueryArray("direction")); len(directions) > 0 {
+			tx = tx.Where("direction IN ?", directions)
+		}
+		if !isInterviewer {
+			var user models.User
+			if err := db.Select("uuid", "directions").Where("uuid = ?", userUUID).First(&user).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "用户不存在"})
+				return
+			}
+			directions := parseJSONList(user.Directions)
+			if len(directions) == 0 {
+				c.JSON(http.StatusOK, gin.H{"ok": true, "data": gin.H{"items": []gin.H{}}})
+				return
+			}
+			tx = tx.Where("direction IN ? AND cancelled_at IS NULL AND starts_at > ?", directions, time.Now())
+		}
+
+		var slots []models.InterviewSlot
+		if err := tx.Order("starts_at ASC").Find(&slots).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		interviewerIds := make([]uuid.UUID, 0, len(slots))
+		slotIds := make([]uuid.UUID, 0, len(slots))
+		for _, slot := range slots {
+			interviewerIds = append(interviewerIds, slot.InterviewerID)
+			slotIds = append(slotIds, slot.UUID)
+		}
+		interviewerNames := loadUserNames(db, interviewerIds)
+
+		// 面试者自己已预约的时间段
+		bookedSlots := make(map[uuid.UUID]struct{})
+		if !isInterviewer && len(slotIds) > 0 {
+			var bookings []models.SlotBooking
+			db.Where("user_id = ? AND status = ? AND slot_id IN ?", userUUID, "booked", slotIds).Find(&bookings)
+			for _, booking := range bookings {
+				bookedSlots[booking.SlotID] = struct{}{}
+			}
+		}
+
+		items := make([]gin.H, 0, len(slots))
+		for _, slot := range slots {
+			_, booked := bookedSlots[slot.UUID]
+			item := slotData(slot, interviewerNames[slot.InterviewerID.String()], isInterviewer || booked)
+			if !isInterviewer {
+				item["bookedByMe"] = booked
+			}
+			items = append(items, item)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// SlotRequest 发布/修改面试时间段请求
+type SlotRequest struct {
+	Direction        string    `json:"direction" binding:"required"`
+	StartsAt         time.Time `json:"startsAt" binding:"required"`
+	Duration         int       `json:"duration" binding:"required,min=5,max=480"`
+	Location         string    `json:"location" binding:"max=200"`
+	OnlineLink       string    `json:"onlineLink" binding:"max=500"`
+	Capacity         int       `json:"capacity" binding:"required,min=1,max=100"`
+	EligibleStatuses []string  `json:"eligibleStatuses" binding:"max=20"`
+	Note             string    `json:"note" binding:"max=500"`
+}
+
+// validate 校验时间段参数
+func (r SlotRequest) validate(db *gorm.DB) string {
+	if !auth.ValidateDirections([]string{r.Direction}) {
+		return "方向参数校验失败"
+	}
+	if r.Location == "" && r.OnlineLink == "" {
+		return "请填写地点或线上链接"
+	}
+	if r.OnlineLink != "" {
+		parsed, err := url.Parse(r.OnlineLink)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "线上链接格式不正确"
+		}
+	}
+	for _, status := range r.EligibleStatuses {
+		if !validateStatus(db, status) {
+			return "可预约状态不存在"
+		}
+	}
+	return ""
+}
+
+// CreateSlot 发布面试时间段（面试官）
+func CreateSlot(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SlotRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		if message := req.validate(db); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": message})
+			return
+		}
+		if !req.StartsAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "开始时间必须晚于当前时间"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		eligibleJSON, _ := json.Marshal(append([]string{}, req.EligibleStatuses...))
+		slotUUID, _ := uuid.NewUUID()
+		slot := models.InterviewSlot{
+			UUID:             slotUUID,
+			SeasonID:         seasonID,
+			InterviewerID:    userUUID,
+			Direction:        req.Direction,
+			StartsAt:         req.StartsAt,
+			Duration:         req.Duration,
+			Location:         req.Location,
+			OnlineLink:       req.OnlineLink,
+			Capacity:         req.Capacity,
+			EligibleStatuses: string(eligibleJSON),
+			Note:             req.Note,
+		}
+		if err := db.Create(&slot).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": slotUUID.String(),
+			},
+		})
+	}
+}
+
+// UpdateSlot 修改面试时间段（发布者或管理员），已有预约时不能修改方向，容量不能小于已预约人数
+func UpdateSlot(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slot, code, message := loadSlot(db, c.Param("id"))
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var req SlotRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !canManageSlot(db, c, *slot) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "只能修改自己发布的时间段"})
+			return
+		}
+		if slot.CancelledAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "时间段已取消"})
+			return
+		}
+		if message := req.validate(db); message != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": message})
+			return
+		}
+		// 与发布时相同，修改后的开始时间必须晚于当前时间
+		if !req.StartsAt.Equal(slot.StartsAt) && !req.StartsAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "开始时间必须晚于当前时间"})
+			return
+		}
+
+		// 递增序号，日历应用据此更新已订阅的事件
+		eligibleJSON, _ := json.Marshal(append([]string{}, req.EligibleStatuses...))
+		updates := map[string]interface{}{
+			"starts_at":         req.StartsAt,
+			"duration":          req.Duration,
+			"location":          req.Location,
+			"online_link":       req.OnlineLink,
+			"capacity":          req.Capacity,
+			"eligible_statuses": string(eligibleJSON),
+			"note":              req.Note,
+			"direction":         req.Direction,
+			"sequence":          gorm.Expr("sequence + 1"),
+		}
+
+		// 条件更新，避免与并发的预约冲突
+		tx := db.Model(&models.InterviewSlot{}).Where("uuid = ? AND booked_count <= ?", slot.UUID, req.Capacity)
+		if req.Direction != slot.Direction {
+			tx = tx.Where("booked_count = 0")
+		}
+		result := tx.Updates(updates)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "已有预约时不能修改方向，容量不能小于已预约人数"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// CancelSlot 取消面试时间段（发布者或管理员），已有的预约一并取消
+func CancelSlot(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slot, code, message := loadSlot(db, c.Param("id"))
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		if !canManageSlot(db, c, *slot) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "只能取消自己发布的时间段"})
+			return
+		}
+		if slot.CancelledAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "时间段已取消"})
+			return
+		}
+
+		now := time.Now()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(slot).Updates(map[string]interface{}{"cancelled_at": now, "booked_count": 0, "sequence": gorm.Expr("sequence + 1")}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.SlotBooking{}).
+				Where("slot_id = ? AND status = ?", slot.UUID, "booked").
+				Updates(map[string]interface{}{"status": "cancelled", "cancelled_at": now}).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// GetSlotBookings 获取时间段的预约名单（面试官）
+func GetSlotBookings(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slot, code, message := loadSlot(db, c.Param("id"))
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var bookings []models.SlotBooking
+		if err := db.Where("slot_id = ?", slot.UUID).Order("created_at ASC").Find(&bookings).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": bookingCandidates(db, loadSeasonBlindView(db, c, slot.SeasonID), bookings),
+			},
+		})
+	}
+}
+
+// bookingCandidates 构建预约名单，包含面试者姓名，匿名评审轮次中的面试者只显示化名
+func bookingCandidates(db *gorm.DB, blind *blindView, bookings []models.SlotBooking) []gin.H {
+	userIds := make([]uuid.UUID, 0, len(bookings))
+	for _, booking := range bookings {
+		userIds = append(userIds, booking.UserID)
+	}
+	userNames := loadUserNames(db, userIds)
+	realNames := loadBookingRealNames(db, bookings)
+	for userUUID := range blind.maskNames(db, userNames) {
+		delete(realNames, userUUID.String())
+	}
+
+	items := make([]gin.H, 0, len(bookings))
+	for _, booking := range bookings {
+		items = append(items, gin.H{
+			"id":          booking.UUID.String(),
+			"userId":      booking.UserID.String(),
+			"name":        template.HTMLEscapeString(userNames[booking.UserID.String()]),
+			"realName":    template.HTMLEscapeString(realNames[booking.UserID.String()]),
+			"status":      booking.Status,
+			"createdAt":   booking.CreatedAt,
+			"cancelledAt": booking.CancelledAt,
+		})
+	}
+	return items
+}
+
+// loadBookingRealNames 批量查询预约者在对应招新季申请中填写的姓名
+func loadBookingRealNames(db *gorm.DB, bookings []models.SlotBooking) map[string]string {
+	realNames := make(map[string]string)
+	if len(bookings) == 0 {
+		return realNames
+	}
+	userIds := make([]uuid.UUID, 0, len(bookings))
+	for _, booking := range bookings {
+		userIds = append(userIds, booking.UserID)
+	}
+	var applications []models.Application
+	db.Select("user_id", "real_name", "season_id").Where("user_id IN ?", userIds).Find(&applications)
+	for _, application := range applications {
+		for _, booking := range bookings {
+			if booking.UserID == application.UserID && booking.SeasonID == application.SeasonID {
+				realNames[booking.UserID.String()] = application.RealName
+			}
+		}
+	}
+	return realNames
+}
+
+// BookSlot 预约面试时间段，成功后发送附带日历文件的确认邮件
+func BookSlot(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		slot, code, message := loadSlot(db, c.Param("id"))
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		var booking *models.SlotBooking
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			booking, code, message, err = reserveSlot(tx, slot, userUUID, time.Now())
+			return err
+		})
+		if errors.Is(err, errBookingRejected) {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		go sendBookingConfirmation(db, *booking, *slot)
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": booking.UUID.String(),
+			},
+		})
+	}
+}
+
+// CancelBooking 取消自己的预约，面试开始前一段时间内不能取消
+func CancelBooking(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		slot, code, message := loadSlot(db, c.Param("id"))
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
+		booking, err := loadActiveBooking(db, slot.UUID, userUUID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "你没有预约该时间段"})
+			return
+		}
+
+		now := time.Now()
+		if now.Add(cancelCutoff).After(slot.StartsAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "已超过取消截止时间，请联系面试官"})
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := lockBookingUser(tx, userUUID); err != nil {
+				return err
+			}
+			return releaseBooking(tx, *booking, now)
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// RescheduleBookingRequest 改约请求
+type RescheduleBookingRequest struct {
+	ToSlotID string `json:"toSlotId" binding:"required"`
+}
+
+// RescheduleBooking 将预约改到同一方向的另一个时间段，新时间段预约失败时保留原预约
+func RescheduleBooking(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RescheduleBookingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		slot, code, message := loadSlot(db, c.Param("id"))
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+		target, code, message := loadSlot(db, req.ToSlotID)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+		if target.UUID == slot.UUID {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "新时间段与原时间段相同"})
+			return
+		}
+		if target.Direction != slot.Direction {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "只能改约到同一方向的时间段"})
+			return
+		}
+
+		if _, err := loadActiveBooking(db, slot.UUID, userUUID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "你没有预约该时间段"})
+			return
+		}
+
+		now := time.Now()
+		if now.Add(cancelCutoff).After(slot.StartsAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "已超过改约截止时间，请联系面试官"})
+			return
+		}
+
+		var newBooking *models.SlotBooking
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// 先锁用户再释放原时间段，与预约、取消的加锁顺序一致
+			if err := lockBookingUser(tx, userUUID); err != nil {
+				return err
+			}
+			active, err := loadActiveBooking(tx, slot.UUID, userUUID)
+			if err != nil {
+				code, message = http.StatusNotFound, "你没有预约该时间段"
+				return errBookingRejected
+			}
+			if err := releaseBooking(tx, *active, now); err != nil {
+				return err
+			}
+			newBooking, code, message, err = reserveSlot(tx, target, userUUID, now)
+			return err
+		})
+		if errors.Is(err, errBookingRejected) {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		go sendBookingConfirmation(db, *newBooking, *target)
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": newBooking.UUID.String(),
+			},
+		})
+	}
+}
+
+// GetMySchedule 获取自己的面试日程
+// 面试官返回自己发布的时间段及预约名单，面试者返回自己的预约；默认只返回未结束的，all=true 时返回全部
+func GetMySchedule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+		upcomingOnly := c.Query("all") != "true"
+		// 进行中的面试也算未结束，按最长时长估算
+		since := time.Now().Add(-480 * time.Minute)
+
+		if GetCurrentUserRole(c) == "interviewer" {
+			tx := db.Where("season_id = ? AND interviewer_id = ? AND cancelled_at IS NULL", season.UUID, userUUID)
+			if upcomingOnly {
+				tx = tx.Where("starts_at > ?", since)
+			}
+			var slots []models.InterviewSlot
+			if err := tx.Order("starts_at ASC").Find(&slots).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+
+			slotIds := make([]uuid.UUID, 0, len(slots))
+			for _, slot := range slots {
+				slotIds = append(slotIds, slot.UUID)
+			}
+			bookingsBySlot := make(map[uuid.UUID][]models.SlotBooking)
+			if len(slotIds) > 0 {
+				var bookings []models.SlotBooking
+				db.Where("slot_id IN ? AND status = ?", slotIds, "booked").Order("created_at ASC").Find(&bookings)
+				for _, booking := range bookings {
+					bookingsBySlot[booking.SlotID] = append(bookingsBySlot[booking.SlotID], booking)
+				}
+			}
+
+			interviewerName := loadUserNames(db, []uuid.UUID{userUUID})[userUUID.String()]
+			blind := loadSeasonBlindView(db, c, season.UUID)
+			items := make([]gin.H, 0, len(slots))
+			for _, slot := range slots {
+				if upcomingOnly && !slot.StartsAt.Add(time.Duration(slot.Duration)*time.Minute).After(time.Now()) {
+					continue
+				}
+				item := slotData(slot, interviewerName, true)
+				item["bookings"] = bookingCandidates(db, blind, bookingsBySlot[slot.UUID])
+				items = append(items, item)
+			}
+
+			c.JSON(http.StatusOK, gin.H{"ok": true, "data": gin.H{"items": items}})
+			return
+		}
+
+		var bookings []models.SlotBooking
+		if err := db.Where("user_id = ? AND season_id = ?", userUUID, season.UUID).Order("created_at DESC").Find(&bookings).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		slotIds := make([]uuid.UUID, 0, len(bookings))
+		for _, booking := range bookings {
+			slotIds = append(slotIds, booking.SlotID)
+		}
+		slotsById := make(map[uuid.UUID]models.InterviewSlot)
+		interviewerIds := make([]uuid.UUID, 0, len(bookings))
+		if len(slotIds) > 0 {
+			var slots []models.InterviewSlot
+			db.Where("uuid IN ?", slotIds).Find(&slots)
+			for _, slot := range slots {
+				slotsById[slot.UUID] = slot
+				interviewerIds = append(interviewerIds, slot.InterviewerID)
+			}
+		}
+		interviewerNames := loadUserNames(db, interviewerIds)
+
+		items := make([]gin.H, 0, len(bookings))
+		for _, booking := range bookings {
+			slot, exists := slotsById[booking.SlotID]
+			if !exists {
+				continue
+			}
+			if upcomingOnly && (booking.Status != "booked" || !slot.StartsAt.Add(time.Duration(slot.Duration)*time.Minute).After(time.Now())) {
+				continue
+			}
+			items = append(items, gin.H{
+				"id":          booking.UUID.String(),
+				"status":      booking.Status,
+				"createdAt":   booking.CreatedAt,
+				"cancelledAt": booking.CancelledAt,
+				"slot":        slotData(slot, interviewerNames[slot.InterviewerID.String()], booking.Status == "booked"),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true, "data": gin.H{"items": items}})
+	}
+}
+
+// releaseUserBookings 在事务中取消符合条件的有效预约并释放名额，用于删除申请或账户
+func releaseUserBookings(tx *gorm.DB, query string, args ...interface{}) error {
+	var bookings []models.SlotBooking
+	if err := tx.Where("status = ?", "booked").Where(query, args...).Find(&bookings).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, booking := range bookings {
+		if err := releaseBooking(tx, booking, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteInterviewerSlots 在事务中删除面试官发布的时间段及其所有预约
+func deleteInterviewerSlots(tx *gorm.DB, interviewerUUID uuid.UUID) error {
+	slotIds := tx.Model(&models.InterviewSlot{}).Select("uuid").Where("interviewer_id = ?", interviewerUUID)
+	if err := tx.Where("slot_id IN (?)", slotIds).Delete(&models.SlotBooking{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("interviewer_id = ?", interviewerUUID).Delete(&models.InterviewSlot{}).Error
+}
@@ -0,0 +1,510 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultStatusTransitions 默认的面试状态流转图，数据库中没有配置时写入
+var defaultStatusTransitions = map[string][]string{
+	"r1_pending": {"r1_passed", "rejected"},
+	"r1_passed":  {"r2_pending", "rejected"},
+	"r2_pending": {"r2_passed", "rejected"},
+	"r2_passed":  {"offer", "rejected"},
+}
+
+// SeedStatusTransitions 初始化默认的状态流转图（仅在表为空时写入）
+func SeedStatusTransitions(db *gorm.DB) {
+	var count int64
+	if err := db.Model(&models.StatusTransition{}).Count(&count).Error; err != nil {
+		log.Printf("读取状态流转配置失败: %v", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	transitions := make([]models.StatusTransition, 0)
+	for from, targets := range defaultStatusTransitions {
+		for _, to := range targets {
+			transitions = append(transitions, models.StatusTransition{FromStatus: from, ToStatus: to})
+		}
+	}
+	if err := db.Create(&transitions).Error; err != nil {
+		log.Printf("写入默认状态流转配置失败: %v", err)
+	}
+}
+
+// statusChangeError 修改面试状态失败的原因，包含应返回的HTTP状态码
+type statusChangeError struct {
+	code    int
+	message string
+}
+
+func (e *statusChangeError) Error() string {
+	return e.message
+}
+
+// statusChange 一次面试状态修改
+type statusChange struct {
+	Status string
+	// Direction 为空时修改所有可流转的方向
+	Direction string
+	Reason    string
+	ActorID   uuid.UUID
+	// Force 为 true 时跳过流转图校验，仅管理员可用
+	Force bool
+	// SeasonID 状态所属的招新季，由 changeInterviewStatus 填充
+	SeasonID uuid.UUID
+}
+
+// changeInterviewStatus 修改面试者的面试状态并记录流转历史
+// SetInterviewStatus、批量修改与看板拖动共用该校验逻辑
+// 状态按方向记录，User.Status 为各方向状态的汇总结果
+func changeInterviewStatus(db *gorm.DB, user *models.User, change statusChange) error {
+	// 声明了利益冲突的面试官不能修改该面试者的状态，管理员强制修改也不例外
+	if hasConflict(db, change.ActorID, user.UUID) {
+		return &statusChangeError{code: http.StatusForbidden, message: conflictMessage}
+	}
+
+	// 验证状态
+	if !validateStatus(db, change.Status) {
+		return &statusChangeError{code: http.StatusBadRequest, message: "要设置的面试状态不合法"}
+	}
+	terminal := terminalStatuses(db)
+
+	seasonID, err := currentSeasonID(db)
+	if err != nil {
+		return &statusChangeError{code: http.StatusInternalServerError, message: "服务器错误"}
+	}
+	change.SeasonID = seasonID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// 锁定面试者后重新读取状态，避免并发修改时按过期的状态校验流转
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", user.UUID).First(user).Error; err != nil {
+			return err
+		}
+
+		directionStatuses, err := loadDirectionStatuses(tx, user)
+		if err != nil {
+			return err
+		}
+
+		// 没有申请方向的用户（旧数据）仍直接修改全局状态
+		if len(directionStatuses) == 0 {
+			if change.Direction != "" {
+				return &statusChangeError{code: http.StatusBadRequest, message: "该面试者未申请此方向"}
+			}
+			return changeGlobalStatus(tx, user, change, terminal)
+		}
+
+		// 确定需要修改的方向
+		targets := make([]models.DirectionStatus, 0, len(directionStatuses))
+		for _, directionStatus := range directionStatuses {
+			if change.Direction != "" && directionStatus.Direction != change.Direction {
+				continue
+			}
+			if directionStatus.Status == change.Status {
+				continue
+			}
+			// 处于终态的方向只能由管理员强制修改
+			if !change.Force && terminal[directionStatus.Status] {
+				if change.Direction != "" {
+					return &statusChangeError{code: http.StatusConflict, message: "该方向已处于终态 " + directionStatus.Status}
+				}
+				continue
+			}
+			if !change.Force && !transitionAllowed(tx, directionStatus.Status, change.Status) {
+				// 指定方向时直接报错，未指定方向时跳过无法流转的方向
+				if change.Direction != "" {
+					return &statusChangeError{code: http.StatusConflict, message: "不允许从 " + directionStatus.Status + " 变更为 " + change.Status}
+				}
+				continue
+			}
+			targets = append(targets, directionStatus)
+		}
+
+		if len(targets) == 0 {
+			if change.Direction != "" && !slices.ContainsFunc(directionStatuses, func(d models.DirectionStatus) bool { return d.Direction == change.Direction }) {
+				return &statusChangeError{code: http.StatusBadRequest, message: "该面试者未申请此方向"}
+			}
+			if change.Direction != "" {
+				return &statusChangeError{code: http.StatusBadRequest, message: "面试状态未发生变化"}
+			}
+			return &statusChangeError{code: http.StatusConflict, message: "没有可以变更为 " + change.Status + " 的方向"}
+		}
+
+		for _, target := range targets {
+			updates := map[string]interface{}{
+				"status":     change.Status,
+				"updated_by": change.ActorID,
+			}
+			if err := tx.Model(&models.DirectionStatus{}).Where("id = ?", target.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+			if err := createStatusChange(tx, user.UUID, target.Direction, target.Status, change); err != nil {
+				return err
+			}
+		}
+		return refreshAggregateStatus(tx, user)
+	})
+	if statusErr, ok := err.(*statusChangeError); ok {
+		return statusErr
+	}
+	if err != nil {
+		return &statusChangeError{code: http.StatusInternalServerError, message: "服务器错误"}
+	}
+
+	return nil
+}
+
+// changeGlobalStatus 直接修改全局状态（用于没有方向记录的用户），需在已锁定面试者的事务中调用
+func changeGlobalStatus(tx *gorm.DB, user *models.User, change statusChange, terminal map[string]bool) error {
+	if user.Status == change.Status {
+		return &statusChangeError{code: http.StatusBadRequest, message: "面试状态未发生变化"}
+	}
+
+	if !change.Force && terminal[user.Status] {
+		return &statusChangeError{code: http.StatusConflict, message: "面试者已处于终态 " + user.Status}
+	}
+
+	// 校验状态流转
+	if !change.Force && !transitionAllowed(tx, user.Status, change.Status) {
+		return &statusChangeError{code: http.StatusConflict, message: "不允许从 " + user.Status + " 变更为 " + change.Status}
+	}
+
+	fromStatus := user.Status
+	if err := tx.Model(user).Update("status", change.Status).Error; err != nil {
+		return err
+	}
+	return createStatusChange(tx, user.UUID, "", fromStatus, change)
+}
+
+// transitionAllowed 检查状态流转图中是否存在 from → to
+func transitionAllowed(db *gorm.DB, from, to string) bool {
+	var transition models.StatusTransition
+	return db.Where("from_status = ? AND to_status = ?", from, to).First(&transition).Error == nil
+}
+
+// createStatusChange 记录一次状态流转
+func createStatusChange(tx *gorm.DB, userUUID uuid.UUID, direction, fromStatus string, change statusChange) error {
+	changeUUID, _ := uuid.NewUUID()
+	return tx.Create(&models.StatusChange{
+		UUID:       changeUUID,
+		UserID:     userUUID,
+		Direction:  direction,
+		FromStatus: fromStatus,
+		ToStatus:   change.Status,
+		Reason:     change.Reason,
+		ActorID:    change.ActorID,
+		Forced:     change.Force,
+		SeasonID:   change.SeasonID,
+	}).Error
+}
+
+// loadDirectionStatuses 查询面试者在当前招新季申请方向的状态记录
+// 旧数据没有方向记录时，按当前全局状态与通过方向补齐
+func loadDirectionStatuses(db *gorm.DB, user *models.User) ([]models.DirectionStatus, error) {
+	directions := parseJSONList(user.Directions)
+	if len(directions) == 0 {
+		return []models.DirectionStatus{}, nil
+	}
+
+	seasonID, err := currentSeasonID(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []models.DirectionStatus
+	if err := db.Where("user_id = ? AND season_id = ?", user.UUID, seasonID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	existingMap := make(map[string]models.DirectionStatus)
+	for _, directionStatus := range existing {
+		existingMap[directionStatus.Direction] = directionStatus
+	}
+
+	// 任何招新季都没有方向记录的才是旧数据，往届成员在新招新季从初始状态开始
+	var total int64
+	if err := db.Model(&models.DirectionStatus{}).Where("user_id = ?", user.UUID).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	legacy := total == 0
+	passedDirections := parseJSONList(user.PassedDirections)
+
+	result := make([]models.DirectionStatus, 0, len(directions))
+	for _, direction := range directions {
+		if directionStatus, exists := existingMap[direction]; exists {
+			result = append(result, directionStatus)
+			continue
+		}
+
+		directionStatus := models.DirectionStatus{
+			UserID:    user.UUID,
+			Direction: direction,
+			Status:    initialStatus(db),
+			SeasonID:  seasonID,
+		}
+		if legacy {
+			directionStatus.Status = user.Status
+			directionStatus.Passed = slices.Contains(passedDirections, direction)
+		}
+		if err := db.Create(&directionStatus).Error; err != nil {
+			return nil, err
+		}
+		result = append(result, directionStatus)
+	}
+
+	return result, nil
+}
+
+// syncDirectionStatuses 在申请方向变化后同步当前招新季的方向状态记录并刷新汇总状态
+func syncDirectionStatuses(db *gorm.DB, userUUID uuid.UUID) error {
+	var user models.User
+	if err := db.Where("uuid = ?", userUUID).First(&user).Error; err != nil {
+		return err
+	}
+
+	seasonID, err := currentSeasonID(db)
+	if err != nil {
+		return err
+	}
+
+	directions := parseJSONList(user.Directions)
+	removed := db.Where("user_id = ? AND season_id = ?", userUUID, seasonID)
+	if len(directions) > 0 {
+		removed = removed.Where("direction NOT IN ?", directions)
+	}
+	if err := removed.Session(&gorm.Session{}).Delete(&models.DirectionStatus{}).Error; err != nil {
+		return err
+	}
+	// 不再申请的方向也不再需要面试官
+	if err := removed.Session(&gorm.Session{}).Delete(&models.Assignment{}).Error; err != nil {
+		return err
+	}
+
+	if _, err := loadDirectionStatuses(db, &user); err != nil {
+		return err
+	}
+	return refreshAggregateStatus(db, &user)
+}
+
+// terminalStatuses 查询所有终态
+func terminalStatuses(db *gorm.DB) map[string]bool {
+	result := make(map[string]bool)
+	definitions, err := loadStatusDefinitions(db)
+	if err != nil {
+		return result
+	}
+	for _, definition := range definitions {
+		if definition.Terminal {
+			result[definition.Key] = true
+		}
+	}
+	return result
+}
+
+// aggregateStatus 由各方向状态汇总出全局状态：取排序最靠后的方向
+// 排序由状态定义中的 sortOrder 决定，默认配置中 rejected 排在最前，全部被拒时才为 rejected
+func aggregateStatus(statuses []string, ranks map[string]int) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+	result := statuses[0]
+	for _, status := range statuses[1:] {
+		if ranks[status] > ranks[result] {
+			result = status
+		}
+	}
+	return result
+}
+
+// refreshAggregateStatus 根据当前招新季的方向状态重新计算 User.Status 与 PassedDirections
+func refreshAggregateStatus(tx *gorm.DB, user *models.User) error {
+	seasonID, err := currentSeasonID(tx)
+	if err != nil {
+		return err
+	}
+
+	var directionStatuses []models.DirectionStatus
+	if err := tx.Where("user_id = ? AND season_id = ?", user.UUID, seasonID).Find(&directionStatuses).Error; err != nil {
+		return err
+	}
+	if len(directionStatuses) == 0 {
+		return nil
+	}
+
+	statuses := make([]string, 0, len(directionStatuses))
+	passedDirections := make([]string, 0)
+	for _, directionStatus := range directionStatuses {
+		statuses = append(statuses, directionStatus.Status)
+		if directionStatus.Passed {
+			passedDirections = append(passedDirections, directionStatus.Direction)
+		}
+	}
+
+	passedJSON, _ := json.Marshal(passedDirections)
+	updates := map[string]interface{}{
+		"status":            aggregateStatus(statuses, statusRanks(tx)),
+		"passed_directions": string(passedJSON),
+	}
+	if err := tx.Model(user).Updates(updates).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// directionStatusData 构建方向状态的返回数据
+func directionStatusData(directionStatuses []models.DirectionStatus) []gin.H {
+	items := make([]gin.H, 0, len(directionStatuses))
+	for _, directionStatus := range directionStatuses {
+		items = append(items, gin.H{
+			"direction": directionStatus.Direction,
+			"status":    directionStatus.Status,
+			"passed":    directionStatus.Passed,
+			"updatedAt": directionStatus.UpdatedAt,
+		})
+	}
+	return items
+}
+
+// statusErrorResponse 将状态修改错误转换为HTTP状态码与提示信息
+func statusErrorResponse(err error) (int, string) {
+	if statusErr, ok := err.(*statusChangeError); ok {
+		return statusErr.code, statusErr.message
+	}
+	return http.StatusInternalServerError, "服务器错误"
+}
+
+// GetStatusTransitions 获取状态流转图（面试官）
+func GetStatusTransitions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var transitions []models.StatusTransition
+		if err := db.Order("from_status ASC, to_status ASC").Find(&transitions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		items := make([]gin.H, 0, len(transitions))
+		for _, transition := range transitions {
+			items = append(items, gin.H{
+				"from": transition.FromStatus,
+				"to":   transition.ToStatus,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// StatusTransitionItem 单条状态流转
+type StatusTransitionItem struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// UpdateStatusTransitionsRequest 更新状态流转图请求
+type UpdateStatusTransitionsRequest struct {
+	Transitions []StatusTransitionItem `json:"transitions" binding:"required,dive"`
+}
+
+// UpdateStatusTransitions 整体替换状态流转图（管理员）
+func UpdateStatusTransitions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateStatusTransitionsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		transitions := make([]models.StatusTransition, 0, len(req.Transitions))
+		seen := make(map[StatusTransitionItem]struct{})
+		for _, item := range req.Transitions {
+			if !validateStatus(db, item.From) || !validateStatus(db, item.To) || item.From == item.To {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "状态流转不合法"})
+				return
+			}
+			if _, exists := seen[item]; exists {
+				continue
+			}
+			seen[item] = struct{}{}
+			transitions = append(transitions, models.StatusTransition{FromStatus: item.From, ToStatus: item.To})
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("1 = 1").Delete(&models.StatusTransition{}).Error; err != nil {
+				return err
+			}
+			if len(transitions) == 0 {
+				return nil
+			}
+			return tx.Create(&transitions).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// GetStatusHistory 获取面试者的状态流转历史（面试官）
+func GetStatusHistory(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, err := uuid.Parse(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "id 参数校验失败"})
+			return
+		}
+
+		var changes []models.StatusChange
+		if err := db.Where("user_id = ?", userUUID).Order("created_at DESC").Find(&changes).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		actorIds := make([]uuid.UUID, 0, len(changes))
+		for _, change := range changes {
+			actorIds = append(actorIds, change.ActorID)
+		}
+		actorNames := loadUserNames(db, actorIds)
+
+		items := make([]gin.H, 0, len(changes))
+		for _, change := range changes {
+			items = append(items, gin.H{
+				"id":        change.UUID.String(),
+				"seasonId":  change.SeasonID.String(),
+				"direction": change.Direction,
+				"from":      change.FromStatus,
+				"to":        change.ToStatus,
+				"reason":    template.HTMLEscapeString(change.Reason),
+				"actorId":   change.ActorID.String(),
+				"actorName": template.HTMLEscapeString(actorNames[change.ActorID.String()]),
+				"forced":    change.Forced,
+				"createdAt": change.CreatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
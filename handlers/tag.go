@@ -0,0 +1,481 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TagRequest 创建/更新标签请求
+type TagRequest struct {
+	Name  string `json:"name" binding:"required,max=32"`
+	Color string `json:"color" binding:"required"`
+}
+
+// GetTags 获取标签列表（面试官）
+func GetTags(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tags []models.Tag
+		if err := db.Order("name ASC").Find(&tags).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		// 统计每个标签关联的面试者数量
+		type tagCount struct {
+			TagID uuid.UUID
+			Count int
+		}
+		var counts []tagCount
+		db.Model(&models.UserTag{}).Select("tag_id, COUNT(*) AS count").Group("tag_id").Scan(&counts)
+		countMap := make(map[uuid.UUID]int)
+		for _, item := range counts {
+			countMap[item.TagID] = item.Count
+		}
+
+		items := make([]gin.H, 0, len(tags))
+		for _, tag := range tags {
+			items = append(items, gin.H{
+				"id":        tag.UUID.String(),
+				"name":      template.HTMLEscapeString(tag.Name),
+				"color":     tag.Color,
+				"userCount": countMap[tag.UUID],
+				"createdAt": tag.CreatedAt,
+				"updatedAt": tag.UpdatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// CreateTag 创建标签（面试官）
+func CreateTag(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req TagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !auth.ValidateColor(req.Color) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "颜色格式不正确"})
+			return
+		}
+
+		// 获取当前用户
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		// 检查标签名是否重复
+		var existingTag models.Tag
+		if err := db.Where("name = ?", req.Name).First(&existingTag).Error; err == nil {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "标签已存在"})
+			return
+		}
+
+		tagUUID, _ := uuid.NewUUID()
+		tag := models.Tag{
+			UUID:      tagUUID,
+			Name:      req.Name,
+			Color:     req.Color,
+			CreatedBy: userUUID,
+		}
+
+		if err := db.Create(&tag).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": tagUUID.String(),
+			},
+		})
+	}
+}
+
+// UpdateTag 更新标签（面试官）
+func UpdateTag(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tagUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req TagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !auth.ValidateColor(req.Color) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "颜色格式不正确"})
+			return
+		}
+
+		var tag models.Tag
+		if err := db.Where("uuid = ?", tagUUID).First(&tag).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "标签不存在"})
+			return
+		}
+
+		// 检查标签名是否与其他标签重复
+		var existingTag models.Tag
+		if err := db.Where("name = ? AND uuid != ?", req.Name, tagUUID).First(&existingTag).Error; err == nil {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": "标签已存在"})
+			return
+		}
+
+		updates := map[string]interface{}{
+			"name":  req.Name,
+			"color": req.Color,
+		}
+		if err := db.Model(&tag).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteTag 删除标签（面试官），同时移除所有面试者上的该标签
+func DeleteTag(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tagUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var tag models.Tag
+		if err := db.Where("uuid = ?", tagUUID).First(&tag).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "标签不存在"})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var userTags []models.UserTag
+			if err := tx.Where("tag_id = ?", tagUUID).Find(&userTags).Error; err != nil {
+				return err
+			}
+
+			// 为每个被移除标签的面试者记录审计日志
+			for _, userTag := range userTags {
+				if err := createTagLog(tx, userTag.UserID, tag, "detach", userUUID); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Where("tag_id = ?", tagUUID).Delete(&models.UserTag{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&tag).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// AttachUserTagRequest 给面试者打标签请求
+type AttachUserTagRequest struct {
+	TagID string `json:"tagId" binding:"required"`
+}
+
+// AttachUserTag 给面试者打标签（面试官）
+func AttachUserTag(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req AttachUserTagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		tagUUID, err := uuid.Parse(req.TagID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		currentUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		// 检查面试者是否存在
+		var user models.User
+		if err := db.Where("uuid = ?", userUUID).First(&user).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "用户不存在"})
+			return
+		}
+		if user.Role != "interviewee" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "目标用户不是面试者"})
+			return
+		}
+
+		var tag models.Tag
+		if err := db.Where("uuid = ?", tagUUID).First(&tag).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "标签不存在"})
+			return
+		}
+
+		// 已打过该标签时不重复记录
+		var existing models.UserTag
+		if err := db.Where("user_id = ? AND tag_id = ?", userUUID, tagUUID).First(&existing).Error; err == nil {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			userTag := models.UserTag{
+				UserID:   userUUID,
+				TagID:    tagUUID,
+				TaggedBy: currentUUID,
+			}
+			if err := tx.Create(&userTag).Error; err != nil {
+				return err
+			}
+			return createTagLog(tx, userUUID, tag, "attach", currentUUID)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DetachUserTag 移除面试者的标签（面试官）
+func DetachUserTag(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		tagUUID, err := uuid.Parse(c.Param("tagId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		currentUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var userTag models.UserTag
+		if err := db.Where("user_id = ? AND tag_id = ?", userUUID, tagUUID).First(&userTag).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该面试者没有这个标签"})
+			return
+		}
+
+		var tag models.Tag
+		if err := db.Where("uuid = ?", tagUUID).First(&tag).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "标签不存在"})
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&userTag).Error; err != nil {
+				return err
+			}
+			return createTagLog(tx, userUUID, tag, "detach", currentUUID)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// GetUserTagLogs 获取面试者的标签变更记录（面试官）
+func GetUserTagLogs(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		items, err := loadTagLogs(db, userUUID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// createTagLog 记录一次标签变更
+func createTagLog(tx *gorm.DB, userUUID uuid.UUID, tag models.Tag, action string, actorUUID uuid.UUID) error {
+	logUUID, _ := uuid.NewUUID()
+	return tx.Create(&models.TagLog{
+		UUID:    logUUID,
+		UserID:  userUUID,
+		TagID:   tag.UUID,
+		TagName: tag.Name,
+		Action:  action,
+		ActorID: actorUUID,
+	}).Error
+}
+
+// loadTagLogs 查询面试者的标签变更记录（按时间倒序）
+func loadTagLogs(db *gorm.DB, userUUID uuid.UUID) ([]gin.H, error) {
+	var logs []models.TagLog
+	if err := db.Where("user_id = ?", userUUID).Order("created_at DESC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	actorIds := make([]uuid.UUID, 0, len(logs))
+	for _, log := range logs {
+		actorIds = append(actorIds, log.ActorID)
+	}
+	actorNames := loadUserNames(db, actorIds)
+
+	items := make([]gin.H, 0, len(logs))
+	for _, log := range logs {
+		items = append(items, gin.H{
+			"id":        log.UUID.String(),
+			"tagId":     log.TagID.String(),
+			"tagName":   template.HTMLEscapeString(log.TagName),
+			"action":    log.Action,
+			"actorId":   log.ActorID.String(),
+			"actorName": template.HTMLEscapeString(actorNames[log.ActorID.String()]),
+			"createdAt": log.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// loadUserTags 批量查询面试者的标签
+func loadUserTags(db *gorm.DB, userIds []uuid.UUID) map[uuid.UUID][]gin.H {
+	result := make(map[uuid.UUID][]gin.H)
+	if len(userIds) == 0 {
+		return result
+	}
+
+	var userTags []models.UserTag
+	if err := db.Where("user_id IN ?", userIds).Order("created_at ASC").Find(&userTags).Error; err != nil {
+		return result
+	}
+
+	tagIds := make([]uuid.UUID, 0, len(userTags))
+	taggerIds := make([]uuid.UUID, 0, len(userTags))
+	for _, userTag := range userTags {
+		tagIds = append(tagIds, userTag.TagID)
+		taggerIds = append(taggerIds, userTag.TaggedBy)
+	}
+
+	tagMap := make(map[uuid.UUID]models.Tag)
+	if len(tagIds) > 0 {
+		var tags []models.Tag
+		if err := db.Where("uuid IN ?", tagIds).Find(&tags).Error; err == nil {
+			for _, tag := range tags {
+				tagMap[tag.UUID] = tag
+			}
+		}
+	}
+	taggerNames := loadUserNames(db, taggerIds)
+
+	for _, userTag := range userTags {
+		tag, exists := tagMap[userTag.TagID]
+		if !exists {
+			continue
+		}
+		result[userTag.UserID] = append(result[userTag.UserID], gin.H{
+			"id":           tag.UUID.String(),
+			"name":         template.HTMLEscapeString(tag.Name),
+			"color":        tag.Color,
+			"taggedBy":     userTag.TaggedBy.String(),
+			"taggedByName": template.HTMLEscapeString(taggerNames[userTag.TaggedBy.String()]),
+			"taggedAt":     userTag.CreatedAt,
+		})
+	}
+	return result
+}
+
+// loadUserTagNames 批量查询面试者的标签名称（未转义，用于导出）
+func loadUserTagNames(db *gorm.DB, userIds []uuid.UUID) map[uuid.UUID][]string {
+	result := make(map[uuid.UUID][]string)
+	if len(userIds) == 0 {
+		return result
+	}
+
+	type userTagName struct {
+		UserID uuid.UUID
+		Name   string
+	}
+	var rows []userTagName
+	if err := db.Table("user_tags").
+		Select("user_tags.user_id, tags.name").
+		Joins("JOIN tags ON tags.uuid = user_tags.tag_id").
+		Where("user_tags.user_id IN ?", userIds).
+		Order("tags.name ASC").
+		Scan(&rows).Error; err != nil {
+		return result
+	}
+
+	for _, row := range rows {
+		result[row.UserID] = append(result[row.UserID], row.Name)
+	}
+	return result
+}
+
+// parseTagFilter 解析标签过滤参数（支持重复参数或逗号分隔）
+func parseTagFilter(values []string) ([]uuid.UUID, error) {
+	tagIds := make([]uuid.UUID, 0, len(values))
+	for _, value := range splitQueryList(values) {
+		tagUUID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, errors.New("invalid tag id")
+		}
+		tagIds = append(tagIds, tagUUID)
+	}
+	return tagIds, nil
+}
@@ -1,19 +1,27 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"time"
 	"xdsec-join-2026/models"
+	"xdsec-join-2026/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateTaskRequest 创建任务请求
 type CreateTaskRequest struct {
-	Title        string `json:"title" binding:"required"`
-	Description  string `json:"description" binding:"required"`
-	TargetUserId string `json:"targetUserId" binding:"required"`
+	Title        string     `json:"title" binding:"required"`
+	Description  string     `json:"description" binding:"required"`
+	TargetUserId string     `json:"targetUserId" binding:"required"`
+	DueAt        *time.Time `json:"dueAt"`
+	// LatePolicy 逾期提交的处理方式：flag 允许提交但标记逾期，reject 拒绝提交，默认为 flag
+	LatePolicy string `json:"latePolicy" binding:"omitempty,oneof=flag reject"`
 }
 
 // CreateTask 创建任务（面试官）
@@ -46,6 +54,18 @@ func CreateTask(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// 任务归属当前招新季
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		latePolicy := req.LatePolicy
+		if latePolicy == "" {
+			latePolicy = "flag"
+		}
+
 		// 创建任务
 		taskUUID, _ := uuid.NewUUID()
 		task := models.Task{
@@ -55,6 +75,10 @@ func CreateTask(db *gorm.DB) gin.HandlerFunc {
 			TargetUserId: targetUUID,
 			AssignedBy:   userUUID,
 			Report:       "",
+			SeasonID:     seasonID,
+			DueAt:        req.DueAt,
+			Status:       "assigned",
+			LatePolicy:   latePolicy,
 		}
 
 		if err := db.Create(&task).Error; err != nil {
@@ -70,6 +94,10 @@ func CreateTask(db *gorm.DB) gin.HandlerFunc {
 type UpdateTaskRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description" binding:"required"`
+	// DueAt 不传时保持原有的截止时间
+	DueAt *time.Time `json:"dueAt"`
+	// LatePolicy 不传时保持原有的逾期处理方式
+	LatePolicy string `json:"latePolicy" binding:"omitempty,oneof=flag reject"`
 }
 
 // UpdateTask 更新任务（面试官）
@@ -119,6 +147,14 @@ func UpdateTask(db *gorm.DB) gin.HandlerFunc {
 			"title":       req.Title,
 			"description": req.Description,
 		}
+		if req.DueAt != nil {
+			updates["due_at"] = *req.DueAt
+			// 修改截止时间后按新的截止时间重新判断已提交的报告是否逾期
+			updates["late"] = task.SubmittedAt != nil && task.SubmittedAt.After(*req.DueAt)
+		}
+		if req.LatePolicy != "" {
+			updates["late_policy"] = req.LatePolicy
+		}
 
 		if err := db.Model(&task).Updates(updates).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
@@ -131,10 +167,19 @@ func UpdateTask(db *gorm.DB) gin.HandlerFunc {
 
 // SubmitTaskReportRequest 提交任务报告请求
 type SubmitTaskReportRequest struct {
-	Report string `json:"report" binding:"required"`
+	Report string `json:"report" binding:"required,max=10000"`
+}
+
+// errTaskRejected 任务状态不允许提交或批阅
+var errTaskRejected = errors.New("task rejected")
+
+// taskLate 当前时间提交是否逾期
+func taskLate(task models.Task, now time.Time) bool {
+	return task.DueAt != nil && now.After(*task.DueAt)
 }
 
 // SubmitTaskReport 提交任务报告（面试者）
+// 任务通过后不能再提交；逾期时按任务的逾期处理方式拒绝提交或标记为逾期
 func SubmitTaskReport(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		taskID := c.Param("id")
@@ -176,8 +221,155 @@ func SubmitTaskReport(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 更新报告
-		if err := db.Model(&task).Update("report", req.Report).Error; err != nil {
+		// 每次提交都保存为新版本，任务上的报告为最新版本（重新提交后需要重新批阅）
+		now := time.Now()
+		var version int
+		var late bool
+		var message string
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// 锁定任务后再检查状态与截止时间，避免与批阅、延期或另一次提交并发时按过期的状态提交
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", task.UUID).First(&task).Error; err != nil {
+				return err
+			}
+			if task.Status == "accepted" {
+				message = "任务已通过，无法再提交"
+				return errTaskRejected
+			}
+			late = taskLate(task, now)
+			if late && task.LatePolicy == "reject" {
+				message = "已超过截止时间，无法提交"
+				return errTaskRejected
+			}
+
+			if err := ensureBaselineReportVersion(tx, task); err != nil {
+				return err
+			}
+			var err error
+			if version, err = createReportVersion(tx, task, req.Report, late, now); err != nil {
+				return err
+			}
+			updates := map[string]interface{}{
+				"report":       req.Report,
+				"status":       "submitted",
+				"submitted_at": now,
+				"late":         late,
+				"reviewed_by":  nil,
+				"reviewed_at":  nil,
+			}
+			return tx.Model(&task).Updates(updates).Error
+		})
+		if errors.Is(err, errTaskRejected) {
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "message": message})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"version": version,
+				"late":    late,
+			},
+		})
+	}
+}
+
+// ReviewTaskRequest 批阅任务请求，version 为批阅时看到的报告版本，status 不传时只标记为已批阅
+type ReviewTaskRequest struct {
+	Version int    `json:"version" binding:"required,min=1"`
+	Status  string `json:"status" binding:"omitempty,oneof=reviewed accepted needs_revision"`
+	Note    string `json:"note" binding:"max=1000"`
+}
+
+// ReviewTask 批阅任务报告（面试官）
+// 已提交或已批阅的任务可以标记为已批阅、通过或需要修改，需要修改的任务由面试者重新提交
+func ReviewTask(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		taskID := c.Param("id")
+		if taskID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req ReviewTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+		if req.Status == "" {
+			req.Status = "reviewed"
+		}
+
+		// 获取当前用户
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		// 解析UUID
+		taskUUID, err := uuid.Parse(taskID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		// 查找任务
+		var task models.Task
+		if err := db.Where("uuid = ?", taskUUID).First(&task).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "任务不存在"})
+			return
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":      req.Status,
+			"review_note": req.Note,
+			"reviewed_by": userUUID,
+			"reviewed_at": now,
+		}
+		var code int
+		var message string
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// 锁定任务后再检查状态与版本，避免面试者同时重新提交时把结论记在未看过的版本上
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", task.UUID).First(&task).Error; err != nil {
+				return err
+			}
+			// 未提交报告的任务无法批阅
+			if task.Report == "" {
+				code, message = http.StatusBadRequest, "面试者尚未提交报告"
+				return errTaskRejected
+			}
+			if task.Status != "submitted" && task.Status != "reviewed" {
+				code, message = http.StatusConflict, "任务已有结论，需要面试者重新提交后才能再次批阅"
+				return errTaskRejected
+			}
+
+			if err := ensureBaselineReportVersion(tx, task); err != nil {
+				return err
+			}
+			latest, err := latestReportVersion(tx, task.UUID)
+			if err != nil {
+				return err
+			}
+			if latest != req.Version {
+				code, message = http.StatusConflict, "报告已更新，请查看最新版本后再批阅"
+				return errTaskRejected
+			}
+
+			if err := markReportVersionReviewed(tx, task, req.Version, req.Status, userUUID, now); err != nil {
+				return err
+			}
+			return tx.Model(&task).Updates(updates).Error
+		})
+		if errors.Is(err, errTaskRejected) {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
@@ -186,6 +378,89 @@ func SubmitTaskReport(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// ExtendTaskDeadlineRequest 延长任务截止时间请求
+type ExtendTaskDeadlineRequest struct {
+	DueAt time.Time `json:"dueAt" binding:"required"`
+}
+
+// ExtendTaskDeadline 为面试者延长任务截止时间（面试官）
+// 首次延长时保留原截止时间；新的截止时间晚于已提交时间时取消逾期标记
+func ExtendTaskDeadline(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		taskUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req ExtendTaskDeadlineRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var task models.Task
+		if err := db.Where("uuid = ?", taskUUID).First(&task).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "任务不存在"})
+			return
+		}
+
+		if task.DueAt == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "任务没有截止时间"})
+			return
+		}
+		if !req.DueAt.After(*task.DueAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "新的截止时间必须晚于原截止时间"})
+			return
+		}
+
+		updates := map[string]interface{}{
+			"due_at":      req.DueAt,
+			"extended_by": userUUID,
+			"late":        task.SubmittedAt != nil && task.SubmittedAt.After(req.DueAt),
+		}
+		if task.OriginalDueAt == nil {
+			updates["original_due_at"] = *task.DueAt
+		}
+		if err := db.Model(&task).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// newTaskSummary 各任务状态的数量，unsubmitted 与 assigned 相同，为兼容旧版前端保留
+func newTaskSummary() gin.H {
+	return gin.H{"total": 0, "unsubmitted": 0, "assigned": 0, "submitted": 0, "reviewed": 0, "accepted": 0, "needs_revision": 0}
+}
+
+// countTaskState 在任务汇总中计入一个任务
+func countTaskState(summary gin.H, state string) {
+	summary["total"] = summary["total"].(int) + 1
+	summary[state] = summary[state].(int) + 1
+	if state == "assigned" {
+		summary["unsubmitted"] = summary["unsubmitted"].(int) + 1
+	}
+}
+
+// SeedTaskStatuses 按报告与批阅情况补齐旧任务的状态
+func SeedTaskStatuses(db *gorm.DB) {
+	if err := db.Model(&models.Task{}).Where("status = ? AND report <> '' AND reviewed_at IS NULL", "assigned").Update("status", "submitted").Error; err != nil {
+		log.Printf("补齐任务状态失败: %v", err)
+	}
+	if err := db.Model(&models.Task{}).Where("status = ? AND report <> '' AND reviewed_at IS NOT NULL", "assigned").Update("status", "reviewed").Error; err != nil {
+		log.Printf("补齐任务状态失败: %v", err)
+	}
+}
+
 // GetTasks 获取任务列表
 func GetTasks(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -208,14 +483,32 @@ func GetTasks(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// 指定招新季，默认为当前招新季
+		season, code, message := resolveSeason(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
+		}
+
 		var tasks []models.Task
-		tx := db.Model(&models.Task{})
+		tx := db.Model(&models.Task{}).Where("season_id = ?", season.UUID)
 
 		// 根据scope过滤
 		if scope == "mine" {
 			tx = tx.Where("target_user_id = ?", userUUID)
 		}
 
+		// 按任务状态过滤
+		if statuses := splitQueryList(c.QueryArray("state")); len(statuses) > 0 {
+			// 兼容旧版的 unsubmitted
+			for i, status := range statuses {
+				if status == "unsubmitted" {
+					statuses[i] = "assigned"
+				}
+			}
+			tx = tx.Where("status IN ?", statuses)
+		}
+
 		// 如果不是面试官且scope为all，只能看到自己的任务
 		if scope == "all" && GetCurrentUserRole(c) != "interviewer" {
 			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "无权限"})
@@ -256,6 +549,17 @@ func GetTasks(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
+		// 匿名评审轮次中的面试者显示化名
+		loadSeasonBlindView(db, c, season.UUID).maskNames(db, userNames)
+
+		// 批量查询报告附件
+		taskIds := make([]uuid.UUID, 0, len(tasks))
+		for _, task := range tasks {
+			taskIds = append(taskIds, task.UUID)
+		}
+		taskAttachments := loadTaskAttachments(db, taskIds)
+		reportVersions := loadLatestReportVersions(db, taskIds)
+
 		items := make([]gin.H, 0, len(tasks))
 		for _, t := range tasks {
 			assignedBy := userNames[t.AssignedBy.String()]
@@ -276,6 +580,16 @@ func GetTasks(db *gorm.DB) gin.HandlerFunc {
 				"targetUserName": targetUserName,
 				"assignedBy":     assignedBy,
 				"report":         t.Report,
+				"reportVersion":  reportVersions[t.UUID],
+				"attachments":    attachmentList(taskAttachments[t.UUID]),
+				"state":          t.Status,
+				"latePolicy":     t.LatePolicy,
+				"late":           t.Late,
+				"submittedAt":    t.SubmittedAt,
+				"reviewNote":     t.ReviewNote,
+				"reviewedAt":     t.ReviewedAt,
+				"dueAt":          t.DueAt,
+				"originalDueAt":  t.OriginalDueAt,
 				"createdAt":      t.CreatedAt,
 				"updatedAt":      t.UpdatedAt,
 			})
@@ -291,7 +605,7 @@ func GetTasks(db *gorm.DB) gin.HandlerFunc {
 }
 
 // DeleteTask 删除任务（面试官）
-func DeleteTask(db *gorm.DB) gin.HandlerFunc {
+func DeleteTask(db *gorm.DB, store storage.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		taskID := c.Param("id")
 		if taskID == "" {
@@ -326,11 +640,22 @@ func DeleteTask(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 删除任务
-		if err := db.Delete(&task).Error; err != nil {
+		// 删除任务及报告附件
+		var attachments []models.Attachment
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if attachments, err = deleteAttachmentRecords(tx, "task_id = ?", task.UUID); err != nil {
+				return err
+			}
+			if err := tx.Where("task_id = ?", task.UUID).Delete(&models.TaskReportVersion{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&task).Error
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
+		purgeAttachmentFiles(store, attachments)
 
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	}
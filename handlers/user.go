@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"html/template"
 	"net/http"
+	"slices"
+	"strings"
 	"xdsec-join-2026/auth"
 	"xdsec-join-2026/models"
+	"xdsec-join-2026/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -26,29 +29,28 @@ func GetUsers(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		currentRole := GetCurrentUserRole(c)
 
-		// 获取查询参数
-		role := c.Query("role")
-		query := c.Query("q")
-
-		// 构建查询
-		tx := db.Model(&models.User{})
-		if currentRole == "interviewer" {
-			tx = tx.Preload("Application")
+		// 获取过滤条件（查询参数或保存视图）
+		filter, code, message := resolveUserFilter(db, c)
+		if code != http.StatusOK {
+			c.JSON(code, gin.H{"ok": false, "message": message})
+			return
 		}
 
-		// 按角色过滤
-		if role != "" {
-			if !auth.ValidateRole(role) {
-				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
-				return
-			}
-			tx = tx.Where("role = ?", role)
+		// 只展示当前招新季的申请
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
 		}
 
-		// 按关键词搜索（昵称或邮箱）
-		if query != "" {
-			tx = tx.Where("nickname LIKE ? OR email LIKE ?", "%"+query+"%", "%"+query+"%")
+		// 构建查询
+		tx := db.Model(&models.User{})
+		if currentRole == "interviewer" {
+			tx = tx.Preload("Application", "season_id = ?", seasonID)
 		}
+		// 匿名评审轮次中的面试者对面试官隐藏身份
+		blind := loadBlindView(db, c, seasonID)
+		tx = filter.Apply(db, tx, currentRole == "interviewer", blind)
 
 		var users []models.User
 		if err := tx.Find(&users).Error; err != nil {
@@ -115,8 +117,9 @@ func GetUsers(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
-		// 面试官视角：预加载任务
-		userTasksMap := make(map[uuid.UUID]interface{})
+		// 面试官视角：预加载任务（同一面试者可能有多个任务）
+		userTasksMap := make(map[uuid.UUID][]gin.H)
+		userTaskSummaryMap := make(map[uuid.UUID]gin.H)
 		if currentRole == "interviewer" {
 			// 收集所有面试者ID
 			intervieweeIds := make([]uuid.UUID, 0)
@@ -129,17 +132,48 @@ func GetUsers(db *gorm.DB) gin.HandlerFunc {
 			// 批量查询任务
 			if len(intervieweeIds) > 0 {
 				var tasks []models.Task
-				if err := db.Where("target_user_id IN ?", intervieweeIds).Find(&tasks).Error; err == nil {
-					// 按面试者分组任务
+				if err := db.Where("target_user_id IN ?", intervieweeIds).Order("created_at DESC").Find(&tasks).Error; err == nil {
+					// 按面试者分组任务并统计各状态数量
 					for _, task := range tasks {
-						userTasksMap[task.TargetUserId] = gin.H{
+						state := task.Status
+						userTasksMap[task.TargetUserId] = append(userTasksMap[task.TargetUserId], gin.H{
 							"id":          task.UUID.String(),
 							"title":       task.Title,
 							"description": task.Description,
 							"report":      task.Report,
+							"state":       state,
+							"late":        task.Late,
+							"dueAt":       task.DueAt,
 							"createdAt":   task.CreatedAt.Format("2006-01-02 15:04:05"),
 							"updatedAt":   task.UpdatedAt.Format("2006-01-02 15:04:05"),
+						})
+
+						summary, exists := userTaskSummaryMap[task.TargetUserId]
+						if !exists {
+							summary = newTaskSummary()
+							userTaskSummaryMap[task.TargetUserId] = summary
 						}
+						countTaskState(summary, state)
+					}
+				}
+			}
+		}
+
+		// 面试官视角：预加载标签与各方向状态
+		userTagsMap := make(map[uuid.UUID][]gin.H)
+		userDirectionStatusMap := make(map[uuid.UUID][]models.DirectionStatus)
+		if currentRole == "interviewer" {
+			userIds := make([]uuid.UUID, 0, len(users))
+			for _, user := range users {
+				userIds = append(userIds, user.UUID)
+			}
+			userTagsMap = loadUserTags(db, userIds)
+
+			if len(userIds) > 0 {
+				var directionStatuses []models.DirectionStatus
+				if err := db.Where("user_id IN ? AND season_id = ?", userIds, seasonID).Find(&directionStatuses).Error; err == nil {
+					for _, directionStatus := range directionStatuses {
+						userDirectionStatusMap[directionStatus.UserID] = append(userDirectionStatusMap[directionStatus.UserID], directionStatus)
 					}
 				}
 			}
@@ -152,6 +186,10 @@ func GetUsers(db *gorm.DB) gin.HandlerFunc {
 			if user.Nickname != nil {
 				nickname = *user.Nickname
 			}
+			masked := blind.masked(user)
+			if masked {
+				nickname = pseudonym(seasonID, user.UUID)
+			}
 
 			userData := gin.H{
 				"id":        user.UUID.String(),
@@ -189,10 +227,33 @@ func GetUsers(db *gorm.DB) gin.HandlerFunc {
 				if user.Application != nil {
 					userData["application"] = user.Application
 				}
+				userData["blind"] = masked
+				if masked {
+					userData["email"] = ""
+					if user.Application != nil {
+						userData["application"] = maskApplication(*user.Application)
+					}
+				}
 
 				// 添加任务
-				if task, exists := userTasksMap[user.UUID]; exists {
-					userData["task"] = task
+				if tasks, exists := userTasksMap[user.UUID]; exists {
+					userData["tasks"] = tasks
+					userData["taskSummary"] = userTaskSummaryMap[user.UUID]
+					// 兼容旧版前端：task 字段保留最新布置的任务
+					userData["task"] = tasks[0]
+				} else {
+					userData["tasks"] = []gin.H{}
+					userData["taskSummary"] = newTaskSummary()
+				}
+
+				// 添加各方向状态
+				userData["directionStatuses"] = directionStatusData(userDirectionStatusMap[user.UUID])
+
+				// 添加标签
+				if tags, exists := userTagsMap[user.UUID]; exists {
+					userData["tags"] = tags
+				} else {
+					userData["tags"] = []gin.H{}
 				}
 
 				// 添加评论
@@ -231,9 +292,15 @@ func GetUserDetail(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 查询用户
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		// 查询用户（只加载当前招新季的申请）
 		var user models.User
-		if err := db.Preload("Application").Where("uuid = ?", userUUID).First(&user).Error; err != nil {
+		if err := db.Preload("Application", "season_id = ?", seasonID).Where("uuid = ?", userUUID).First(&user).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "用户不存在"})
 			return
 		}
@@ -247,6 +314,15 @@ func GetUserDetail(db *gorm.DB) gin.HandlerFunc {
 			"status":    user.Status,
 		}
 
+		// 匿名评审轮次中的面试者对面试官隐藏身份
+		blind := loadBlindView(db, c, seasonID)
+		masked := blind.masked(user)
+		userData["blind"] = masked
+		if masked {
+			userData["email"] = ""
+			userData["nickname"] = pseudonym(seasonID, user.UUID)
+		}
+
 		// 解析Directions
 		if user.Directions != "" {
 			var directions []string
@@ -288,7 +364,75 @@ func GetUserDetail(db *gorm.DB) gin.HandlerFunc {
 			}
 
 			userData["application"] = appData
+
+			// 本招新季中手机号、学号或姓名相同的其他申请者，匿名时不展示，需要隐藏身份的其他申请者也不列出
+			userData["duplicates"] = []gin.H{}
+			if masked {
+				maskApplicationData(appData)
+			} else {
+				duplicates, err := findDuplicateApplications(db, blind, *app)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+					return
+				}
+				userData["duplicates"] = duplicates
+			}
+		} else {
+			userData["duplicates"] = []gin.H{}
+		}
+
+		// 包含各方向状态
+		directionStatuses, err := loadDirectionStatuses(db, &user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		userData["directionStatuses"] = directionStatusData(directionStatuses)
+
+		// 包含各方向分配的面试官
+		var assignments []models.Assignment
+		if err := db.Where("season_id = ? AND user_id = ?", seasonID, user.UUID).Order("direction ASC").Find(&assignments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		interviewerIds := make([]uuid.UUID, 0, len(assignments))
+		for _, assignment := range assignments {
+			interviewerIds = append(interviewerIds, assignment.InterviewerID)
+		}
+		interviewerNames := loadUserNames(db, interviewerIds)
+		assignmentItems := make([]gin.H, 0, len(assignments))
+		for _, assignment := range assignments {
+			assignmentItems = append(assignmentItems, gin.H{
+				"id":              assignment.UUID.String(),
+				"direction":       assignment.Direction,
+				"interviewerId":   assignment.InterviewerID.String(),
+				"interviewerName": template.HTMLEscapeString(interviewerNames[assignment.InterviewerID.String()]),
+			})
+		}
+		userData["assignments"] = assignmentItems
+
+		// 利益冲突声明仅管理员可见
+		if currentUUID, ok := GetCurrentUserUUID(c); ok && IsAdmin(db, currentUUID) {
+			var conflicts []models.ConflictOfInterest
+			if err := db.Where("user_id = ?", user.UUID).Order("created_at DESC").Find(&conflicts).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			userData["conflicts"] = conflictItems(db, nil, conflicts)
+		}
+
+		// 包含标签及标签变更记录
+		if tags, exists := loadUserTags(db, []uuid.UUID{user.UUID})[user.UUID]; exists {
+			userData["tags"] = tags
+		} else {
+			userData["tags"] = []gin.H{}
 		}
+		tagLogs, err := loadTagLogs(db, user.UUID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		userData["tagLogs"] = tagLogs
 
 		c.JSON(http.StatusOK, gin.H{
 			"ok": true,
@@ -499,6 +643,32 @@ func SetPassedDirections(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		// 检查利益冲突
+		if hasConflict(db, currentUUID, userUUID) {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": conflictMessage})
+			return
+		}
+
+		// 查询各方向的状态记录，通过方向必须是面试者申请的方向
+		directionStatuses, err := loadDirectionStatuses(db, &user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		for _, direction := range req.Directions {
+			applied := false
+			for _, directionStatus := range directionStatuses {
+				if directionStatus.Direction == direction {
+					applied = true
+					break
+				}
+			}
+			if len(directionStatuses) > 0 && !applied {
+				c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "面试者未申请方向 " + direction})
+				return
+			}
+		}
+
 		// 序列化方向
 		directionsJSON, _ := json.Marshal(req.Directions)
 
@@ -531,13 +701,32 @@ func SetPassedDirections(db *gorm.DB) gin.HandlerFunc {
 		// 序列化数组
 		passedByJSON, _ := json.Marshal(passedByList)
 
-		// 更新
-		updates := map[string]interface{}{
-			"passed_directions":    string(directionsJSON),
-			"passed_directions_by": string(passedByJSON),
-		}
+		// 更新各方向的通过标记，并同步汇总的 passedDirections
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, directionStatus := range directionStatuses {
+				passed := slices.Contains(req.Directions, directionStatus.Direction)
+				if passed == directionStatus.Passed {
+					continue
+				}
+				updates := map[string]interface{}{
+					"passed":     passed,
+					"updated_by": currentUUID,
+				}
+				if err := tx.Model(&models.DirectionStatus{}).Where("id = ?", directionStatus.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
 
-		if err := db.Model(&user).Updates(updates).Error; err != nil {
+			updates := map[string]interface{}{
+				"passed_directions":    string(directionsJSON),
+				"passed_directions_by": string(passedByJSON),
+			}
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+			return refreshAggregateStatus(tx, &user)
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
@@ -546,8 +735,49 @@ func SetPassedDirections(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// deleteUserData 在事务中删除用户及其关联数据（会级联删除关联的申请）
+// 返回被删除的附件，提交后再用 purgeAttachmentFiles 删除文件
+func deleteUserData(tx *gorm.DB, user models.User) ([]models.Attachment, error) {
+	// 删除用户上传的附件、面试官分配、利益冲突声明、评分、重复忽略记录、日历订阅与任务报告历史版本，释放面试预约，面试官发布的时间段连同预约一并删除
+	attachments, err := deleteAttachmentRecords(tx, "owner_id = ?", user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := releaseUserBookings(tx, "user_id = ?", user.UUID); err != nil {
+		return nil, err
+	}
+	if err := deleteInterviewerSlots(tx, user.UUID); err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ? OR interviewer_id = ?", user.UUID, user.UUID).Delete(&models.Assignment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ? OR interviewer_id = ?", user.UUID, user.UUID).Delete(&models.ConflictOfInterest{}).Error; err != nil {
+		return nil, err
+	}
+	if err := deleteScorecards(tx, "user_id = ? OR interviewer_id = ?", user.UUID, user.UUID); err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.UUID).Delete(&models.BlindReveal{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_a = ? OR user_b = ?", user.UUID, user.UUID).Delete(&models.DuplicateDismissal{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.UUID).Delete(&models.CalendarToken{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.UUID).Delete(&models.TaskReportVersion{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Delete(&user).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
 // DeleteUser 删除用户（面试官）
-func DeleteUser(db *gorm.DB) gin.HandlerFunc {
+func DeleteUser(db *gorm.DB, store storage.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.Param("id")
 		if userID == "" {
@@ -569,18 +799,24 @@ func DeleteUser(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 删除用户（会级联删除关联的申请）
-		if err := db.Delete(&user).Error; err != nil {
+		var attachments []models.Attachment
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			attachments, err = deleteUserData(tx, user)
+			return err
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
+		purgeAttachmentFiles(store, attachments)
 
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	}
 }
 
 // DeleteSelf 删除自己的账户
-func DeleteSelf(db *gorm.DB) gin.HandlerFunc {
+func DeleteSelf(db *gorm.DB, store storage.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req struct {
 			EmailCode string `json:"emailCode" binding:"required"`
@@ -610,12 +846,52 @@ func DeleteSelf(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// 删除用户（会级联删除关联的申请）
-		if err := db.Delete(&user).Error; err != nil {
+		var attachments []models.Attachment
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			attachments, err = deleteUserData(tx, user)
+			return err
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
 			return
 		}
+		purgeAttachmentFiles(store, attachments)
 
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	}
 }
+
+// loadUserNames 批量查询用户显示名称（优先昵称，其次邮箱）
+func loadUserNames(db *gorm.DB, userIds []uuid.UUID) map[string]string {
+	names := make(map[string]string)
+	if len(userIds) == 0 {
+		return names
+	}
+
+	var users []models.User
+	if err := db.Select("uuid", "nickname", "email").Where("uuid IN ?", userIds).Find(&users).Error; err == nil {
+		for _, user := range users {
+			name := user.Email
+			if user.Nickname != nil && *user.Nickname != "" {
+				name = *user.Nickname
+			}
+			names[user.UUID.String()] = name
+		}
+	}
+	return names
+}
+
+// splitQueryList 展开重复或逗号分隔的查询参数
+func splitQueryList(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
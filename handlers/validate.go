@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"xdsec-join-2026/models"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// phonePattern 中国大陆手机号
+var phonePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// studentIdPattern 学号格式，命名分组 year 为入学年份（两位或四位）
+var studentIdPattern = regexp.MustCompile(`^(?P<year>\d{2})\d{9}$`)
+
+// maxEnrollmentYears 入学年份最多早于当前年份的年数
+const maxEnrollmentYears = 10
+
+// SetStudentIdPattern 设置学号格式，必须包含命名分组 year
+func SetStudentIdPattern(pattern string) error {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	if compiled.SubexpIndex("year") < 0 {
+		return errors.New("学号格式缺少 year 分组")
+	}
+	studentIdPattern = compiled
+	return nil
+}
+
+// applicationValidator 申请字段校验器，可以规范化字段的值，返回空字符串表示通过
+type applicationValidator struct {
+	Field string
+	Check func(db *gorm.DB, req *CreateApplicationRequest) string
+}
+
+// applicationValidators 提交申请时依次执行的字段校验
+var applicationValidators = []applicationValidator{
+	{Field: "phone", Check: validatePhone},
+	{Field: "studentId", Check: validateStudentId},
+	{Field: "department", Check: validateDepartment},
+	{Field: "major", Check: validateMajor},
+}
+
+// validateApplicationFields 执行所有字段校验，返回以字段名为键的错误信息
+func validateApplicationFields(db *gorm.DB, req *CreateApplicationRequest) map[string]string {
+	errs := make(map[string]string)
+	for _, v := range applicationValidators {
+		if _, failed := errs[v.Field]; failed {
+			continue
+		}
+		if message := v.Check(db, req); message != "" {
+			errs[v.Field] = message
+		}
+	}
+	return errs
+}
+
+// normalizePhone 去掉手机号中的空格、连字符与 +86 前缀
+func normalizePhone(phone string) string {
+	phone = strings.NewReplacer(" ", "", "-", "").Replace(phone)
+	return strings.TrimPrefix(strings.TrimPrefix(phone, "+86"), "86")
+}
+
+// validatePhone 手机号规范化后校验，长度由格式限定为 11 位
+func validatePhone(db *gorm.DB, req *CreateApplicationRequest) string {
+	phone := normalizePhone(req.Phone)
+	if !phonePattern.MatchString(phone) {
+		return "请输入有效的手机号"
+	}
+	req.Phone = phone
+	return ""
+}
+
+// validateStudentId 校验学号格式并检查入学年份
+func validateStudentId(db *gorm.DB, req *CreateApplicationRequest) string {
+	studentId := strings.ToUpper(strings.TrimSpace(req.StudentId))
+	if _, ok := enrollmentYear(studentId, time.Now()); !ok {
+		return "请输入有效的学号"
+	}
+	req.StudentId = studentId
+	return ""
+}
+
+// enrollmentYear 从学号中提取入学年份，学号不合法或年份不合理时返回 false
+func enrollmentYear(studentId string, now time.Time) (int, bool) {
+	match := studentIdPattern.FindStringSubmatch(studentId)
+	if match == nil {
+		return 0, false
+	}
+	year, err := strconv.Atoi(match[studentIdPattern.SubexpIndex("year")])
+	if err != nil {
+		return 0, false
+	}
+	if year < 100 {
+		year += 2000
+	}
+	if year > now.Year() || year < now.Year()-maxEnrollmentYears {
+		return 0, false
+	}
+	return year, true
+}
+
+// validateDepartment 学院需要在目录中，目录为空时不校验
+func validateDepartment(db *gorm.DB, req *CreateApplicationRequest) string {
+	req.Department = strings.TrimSpace(req.Department)
+
+	var count int64
+	if err := db.Model(&models.Department{}).Count(&count).Error; err != nil || count == 0 {
+		return ""
+	}
+	if err := db.Where("name = ?", req.Department).First(&models.Department{}).Error; err != nil {
+		return "请从列表中选择学院"
+	}
+	return ""
+}
+
+// validateMajor 学院在目录中且配置了专业时，专业需要属于该学院
+func validateMajor(db *gorm.DB, req *CreateApplicationRequest) string {
+	req.Major = strings.TrimSpace(req.Major)
+
+	var department models.Department
+	if err := db.Where("name = ?", req.Department).First(&department).Error; err != nil {
+		return ""
+	}
+	var count int64
+	if err := db.Model(&models.Major{}).Where("department_id = ?", department.ID).Count(&count).Error; err != nil || count == 0 {
+		return ""
+	}
+	if err := db.Where("department_id = ? AND name = ?", department.ID, req.Major).First(&models.Major{}).Error; err != nil {
+		return "请从列表中选择专业"
+	}
+	return ""
+}
+
+// bindingErrors 将请求绑定的校验错误转换为以 json 字段名为键的错误信息，无法转换时返回 nil
+func bindingErrors(err error, obj interface{}) map[string]string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	objType := reflect.TypeOf(obj)
+	for objType.Kind() == reflect.Ptr {
+		objType = objType.Elem()
+	}
+
+	errs := make(map[string]string)
+	for _, fieldErr := range validationErrs {
+		// 嵌套字段的错误归到顶层字段上
+		structField := fieldErr.StructField()
+		if parts := strings.Split(fieldErr.StructNamespace(), "."); len(parts) > 1 {
+			structField, _, _ = strings.Cut(parts[1], "[")
+		}
+		name := structField
+		if field, ok := objType.FieldByName(structField); ok {
+			if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" {
+				name = tag
+			}
+		}
+		if _, exists := errs[name]; exists {
+			continue
+		}
+		switch fieldErr.Tag() {
+		case "required":
+			errs[name] = "不能为空"
+		case "max":
+			errs[name] = fmt.Sprintf("长度不能超过 %s", fieldErr.Param())
+		default:
+			errs[name] = "格式不正确"
+		}
+	}
+	return errs
+}
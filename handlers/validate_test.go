@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw   string
+		want  string
+		valid bool
+	}{
+		{"13812345678", "13812345678", true},
+		{"138 1234 5678", "13812345678", true},
+		{"138-1234-5678", "13812345678", true},
+		{"+86 138-1234-5678", "13812345678", true},
+		{"+86-138 1234 5678", "13812345678", true},
+		{"8613812345678", "13812345678", true},
+		{"12812345678", "12812345678", false},
+		{"1381234567", "1381234567", false},
+		{"+86 138-1234-56789", "138123456789", false},
+	}
+
+	for _, tt := range tests {
+		got := normalizePhone(tt.raw)
+		if got != tt.want {
+			t.Errorf("normalizePhone(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+		if valid := phonePattern.MatchString(got); valid != tt.valid {
+			t.Errorf("phonePattern.MatchString(%q) = %v, want %v", got, valid, tt.valid)
+		}
+	}
+}
+
+func TestEnrollmentYear(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		studentId string
+		want      int
+		ok        bool
+	}{
+		{"24009100001", 2024, true},
+		{"26009100001", 2026, true},
+		{"16009100001", 2016, true},
+		{"15009100001", 0, false},
+		{"27009100001", 0, false},
+		{"2400910000", 0, false},
+		{"A4009100001", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := enrollmentYear(tt.studentId, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("enrollmentYear(%q) = %d, %v, want %d, %v", tt.studentId, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedViewRequest 创建/更新保存视图请求
+type SavedViewRequest struct {
+	Name    string     `json:"name" binding:"required,max=50"`
+	Shared  bool       `json:"shared"`
+	Filters UserFilter `json:"filters"`
+}
+
+// GetViews 获取保存视图列表（自己的与团队共享的）
+func GetViews(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var views []models.SavedView
+		if err := db.Where("owner_id = ? OR shared = ?", userUUID, true).Order("created_at ASC").Find(&views).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		ownerIds := make([]uuid.UUID, 0, len(views))
+		for _, view := range views {
+			ownerIds = append(ownerIds, view.OwnerID)
+		}
+		ownerNames := loadUserNames(db, ownerIds)
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+		blind := loadBlindView(db, c, seasonID)
+
+		items := make([]gin.H, 0, len(views))
+		for _, view := range views {
+			filter, err := savedViewFilter(view)
+			if err != nil {
+				continue
+			}
+
+			// 统计当前符合条件的人数
+			var memberCount int64
+			filter.Apply(db, db.Model(&models.User{}), true, blind).Count(&memberCount)
+
+			items = append(items, gin.H{
+				"id":          view.UUID.String(),
+				"name":        template.HTMLEscapeString(view.Name),
+				"ownerId":     view.OwnerID.String(),
+				"ownerName":   template.HTMLEscapeString(ownerNames[view.OwnerID.String()]),
+				"shared":      view.Shared,
+				"mine":        view.OwnerID == userUUID,
+				"filters":     filter,
+				"memberCount": memberCount,
+				"createdAt":   view.CreatedAt,
+				"updatedAt":   view.UpdatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"items": items,
+			},
+		})
+	}
+}
+
+// CreateView 创建保存视图（面试官）
+func CreateView(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SavedViewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if err := req.Filters.Validate(db); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "过滤条件校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		filtersJSON, _ := json.Marshal(req.Filters)
+		viewUUID, _ := uuid.NewUUID()
+		view := models.SavedView{
+			UUID:    viewUUID,
+			Name:    req.Name,
+			OwnerID: userUUID,
+			Shared:  req.Shared,
+			Filters: string(filtersJSON),
+		}
+
+		if err := db.Create(&view).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"id": viewUUID.String(),
+			},
+		})
+	}
+}
+
+// UpdateView 更新保存视图（仅创建者）
+func UpdateView(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		viewUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var req SavedViewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if err := req.Filters.Validate(db); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "过滤条件校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var view models.SavedView
+		if err := db.Where("uuid = ?", viewUUID).First(&view).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "视图不存在"})
+			return
+		}
+
+		if view.OwnerID != userUUID {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "只能修改自己创建的视图"})
+			return
+		}
+
+		filtersJSON, _ := json.Marshal(req.Filters)
+		updates := map[string]interface{}{
+			"name":    req.Name,
+			"shared":  req.Shared,
+			"filters": string(filtersJSON),
+		}
+		if err := db.Model(&view).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteView 删除保存视图（仅创建者）
+func DeleteView(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		viewUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var view models.SavedView
+		if err := db.Where("uuid = ?", viewUUID).First(&view).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "视图不存在"})
+			return
+		}
+
+		if view.OwnerID != userUUID {
+			c.JSON(http.StatusForbidden, gin.H{"ok": false, "message": "只能删除自己创建的视图"})
+			return
+		}
+
+		if err := db.Delete(&view).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// loadAccessibleView 查询当前用户可以使用的视图（自己创建的或共享的）
+func loadAccessibleView(db *gorm.DB, viewUUID, userUUID uuid.UUID) (*models.SavedView, error) {
+	var view models.SavedView
+	if err := db.Where("uuid = ? AND (owner_id = ? OR shared = ?)", viewUUID, userUUID, true).First(&view).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// savedViewFilter 解析视图中保存的过滤条件
+func savedViewFilter(view models.SavedView) (UserFilter, error) {
+	var filter UserFilter
+	if view.Filters == "" {
+		return filter, nil
+	}
+	if err := json.Unmarshal([]byte(view.Filters), &filter); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
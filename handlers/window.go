@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"slices"
+	"time"
+	"xdsec-join-2026/auth"
+	"xdsec-join-2026/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 申请时间窗口相关的错误码，前端据此展示倒计时或截止提示
+const (
+	windowCodeNotOpen = "application_not_open"
+	windowCodeClosed  = "application_closed"
+)
+
+// windowError 当前时间不在申请开放时间内
+type windowError struct {
+	Code      string
+	Message   string
+	Direction string
+	OpensAt   time.Time
+	ClosesAt  time.Time
+}
+
+// loadDirectionWindows 查询招新季中单独配置了开放时间的方向
+func loadDirectionWindows(db *gorm.DB, seasonUUID uuid.UUID) (map[string]models.DirectionWindow, error) {
+	var windows []models.DirectionWindow
+	if err := db.Where("season_id = ?", seasonUUID).Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]models.DirectionWindow)
+	for _, window := range windows {
+		result[window.Direction] = window
+	}
+	return result, nil
+}
+
+// loadApplicationExtension 查询面试者在招新季中获得的延期
+func loadApplicationExtension(db *gorm.DB, seasonUUID, userUUID uuid.UUID) *models.ApplicationExtension {
+	var extension models.ApplicationExtension
+	if err := db.Where("season_id = ? AND user_id = ?", seasonUUID, userUUID).First(&extension).Error; err != nil {
+		return nil
+	}
+	return &extension
+}
+
+// checkApplicationWindow 检查当前时间是否允许提交或修改涉及这些方向的申请
+// 方向单独配置了开放时间时以方向为准，否则使用招新季的开放时间；延期只推迟截止时间
+func checkApplicationWindow(db *gorm.DB, season *models.Season, userUUID uuid.UUID, directions []string, now time.Time) (*windowError, error) {
+	windows, err := loadDirectionWindows(db, season.UUID)
+	if err != nil {
+		return nil, err
+	}
+	extension := loadApplicationExtension(db, season.UUID, userUUID)
+
+	check := func(direction string, opensAt, closesAt time.Time) *windowError {
+		if extension != nil && extension.Until.After(closesAt) {
+			closesAt = extension.Until
+		}
+		if now.Before(opensAt) {
+			return &windowError{Code: windowCodeNotOpen, Message: "申请尚未开放", Direction: direction, OpensAt: opensAt, ClosesAt: closesAt}
+		}
+		if now.After(closesAt) {
+			return &windowError{Code: windowCodeClosed, Message: "申请已截止", Direction: direction, OpensAt: opensAt, ClosesAt: closesAt}
+		}
+		return nil
+	}
+
+	if len(directions) == 0 {
+		return check("", season.OpensAt, season.ClosesAt), nil
+	}
+	for _, direction := range directions {
+		opensAt, closesAt := season.OpensAt, season.ClosesAt
+		if window, exists := windows[direction]; exists {
+			opensAt, closesAt = window.OpensAt, window.ClosesAt
+		}
+		if windowErr := check(direction, opensAt, closesAt); windowErr != nil {
+			windowErr.Message = "方向 " + direction + " " + windowErr.Message
+			return windowErr, nil
+		}
+	}
+	return nil, nil
+}
+
+// windowErrorBody 构建带错误码与开放时间的响应内容
+func windowErrorBody(windowErr *windowError) gin.H {
+	return gin.H{
+		"ok":      false,
+		"code":    windowErr.Code,
+		"message": windowErr.Message,
+		"window": gin.H{
+			"direction": windowErr.Direction,
+			"opensAt":   windowErr.OpensAt,
+			"closesAt":  windowErr.ClosesAt,
+			"now":       time.Now(),
+		},
+	}
+}
+
+// GetApplicationWindow 获取当前招新季的申请开放时间（含方向单独配置与自己的延期）
+func GetApplicationWindow(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		season, err := currentSeason(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		windows, err := loadDirectionWindows(db, season.UUID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		now := time.Now()
+		directionItems := make([]gin.H, 0, len(windows))
+		for _, window := range windows {
+			windowErr, err := checkApplicationWindow(db, season, userUUID, []string{window.Direction}, now)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+				return
+			}
+			directionItems = append(directionItems, gin.H{
+				"direction": window.Direction,
+				"opensAt":   window.OpensAt,
+				"closesAt":  window.ClosesAt,
+				"open":      windowErr == nil,
+			})
+		}
+		slices.SortFunc(directionItems, func(a, b gin.H) int {
+			return a["opensAt"].(time.Time).Compare(b["opensAt"].(time.Time))
+		})
+
+		var extensionData gin.H
+		if extension := loadApplicationExtension(db, season.UUID, userUUID); extension != nil {
+			extensionData = gin.H{
+				"until":  extension.Until,
+				"reason": template.HTMLEscapeString(extension.Reason),
+			}
+		}
+
+		windowErr, err := checkApplicationWindow(db, season, userUUID, nil, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+			"data": gin.H{
+				"season":     seasonData(*season),
+				"opensAt":    season.OpensAt,
+				"closesAt":   season.ClosesAt,
+				"open":       windowErr == nil,
+				"directions": directionItems,
+				"extension":  extensionData,
+				"now":        now,
+			},
+		})
+	}
+}
+
+// DirectionWindowRequest 设置方向开放时间请求
+type DirectionWindowRequest struct {
+	OpensAt  time.Time `json:"opensAt" binding:"required"`
+	ClosesAt time.Time `json:"closesAt" binding:"required"`
+}
+
+// SetDirectionWindow 为招新季中的某个方向单独设置开放时间（管理员）
+func SetDirectionWindow(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		direction := c.Param("direction")
+		if !auth.ValidateDirection(direction) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "方向参数校验失败"})
+			return
+		}
+
+		var req DirectionWindowRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !req.ClosesAt.After(req.OpensAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "截止时间必须晚于开放时间"})
+			return
+		}
+
+		var season models.Season
+		if err := db.Where("uuid = ?", seasonUUID).First(&season).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "招新季不存在"})
+			return
+		}
+
+		var window models.DirectionWindow
+		err = db.Where("season_id = ? AND direction = ?", seasonUUID, direction).First(&window).Error
+		if err == nil {
+			updates := map[string]interface{}{
+				"opens_at":  req.OpensAt,
+				"closes_at": req.ClosesAt,
+			}
+			err = db.Model(&window).Updates(updates).Error
+		} else {
+			window = models.DirectionWindow{
+				SeasonID:  seasonUUID,
+				Direction: direction,
+				OpensAt:   req.OpensAt,
+				ClosesAt:  req.ClosesAt,
+			}
+			err = db.Create(&window).Error
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// DeleteDirectionWindow 删除方向的单独开放时间，恢复使用招新季的开放时间（管理员）
+func DeleteDirectionWindow(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		var window models.DirectionWindow
+		if err := db.Where("season_id = ? AND direction = ?", seasonUUID, c.Param("direction")).First(&window).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该方向没有单独的开放时间"})
+			return
+		}
+
+		if err := db.Delete(&window).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// GrantExtensionRequest 延期请求
+type GrantExtensionRequest struct {
+	Until  time.Time `json:"until" binding:"required"`
+	Reason string    `json:"reason" binding:"max=200"`
+}
+
+// GrantApplicationExtension 为面试者单独延长当前招新季的申请截止时间（面试官）
+func GrantApplicationExtension(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, err := uuid.Parse(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "id 参数校验失败"})
+			return
+		}
+
+		var req GrantExtensionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "参数校验失败"})
+			return
+		}
+
+		if !req.Until.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "延期时间必须晚于当前时间"})
+			return
+		}
+
+		currentUUID, ok := GetCurrentUserUUID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "message": "未登录"})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("uuid = ? AND role = ?", userUUID, "interviewee").First(&user).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "面试者不存在"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var extension models.ApplicationExtension
+		err = db.Where("season_id = ? AND user_id = ?", seasonID, userUUID).First(&extension).Error
+		if err == nil {
+			updates := map[string]interface{}{
+				"until":      req.Until,
+				"reason":     req.Reason,
+				"granted_by": currentUUID,
+			}
+			err = db.Model(&extension).Updates(updates).Error
+		} else {
+			extension = models.ApplicationExtension{
+				SeasonID:  seasonID,
+				UserID:    userUUID,
+				Until:     req.Until,
+				Reason:    req.Reason,
+				GrantedBy: currentUUID,
+			}
+			err = db.Create(&extension).Error
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// RevokeApplicationExtension 撤销面试者的延期（面试官）
+func RevokeApplicationExtension(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, err := uuid.Parse(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "message": "id 参数校验失败"})
+			return
+		}
+
+		seasonID, err := currentSeasonID(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		var extension models.ApplicationExtension
+		if err := db.Where("season_id = ? AND user_id = ?", seasonID, userUUID).First(&extension).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "message": "该面试者没有延期"})
+			return
+		}
+
+		if err := db.Delete(&extension).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "message": "服务器错误"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
@@ -0,0 +1,116 @@
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event 日历中的一个事件
+type Event struct {
+	// UID 事件的唯一标识，同一事件每次生成时必须一致，日历应用据此更新或取消事件
+	UID string
+	// Sequence 事件的修订序号，修改时间或地点后需要递增
+	Sequence int
+	Start    time.Time
+	// End 为零值时表示没有持续时间的时间点（如截止时间）
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Cancelled    bool
+	LastModified time.Time
+}
+
+// Calendar 一个 iCalendar 对象（RFC 5545）
+type Calendar struct {
+	// Name 日历名称，部分日历应用订阅时作为显示名称
+	Name string
+	// Method 为空时不输出，邮件附件使用 PUBLISH
+	Method string
+	Events []Event
+}
+
+// Encode 生成 iCalendar 文本，now 作为各事件的 DTSTAMP
+func (c Calendar) Encode(now time.Time) []byte {
+	var buf bytes.Buffer
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//XDSEC//Recruitment System//ZH")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	if c.Method != "" {
+		writeLine(&buf, "METHOD:"+c.Method)
+	}
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	for _, event := range c.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+event.UID)
+		writeLine(&buf, "DTSTAMP:"+formatTime(now))
+		writeLine(&buf, "SEQUENCE:"+strconv.Itoa(event.Sequence))
+		writeLine(&buf, "DTSTART:"+formatTime(event.Start))
+		if !event.End.IsZero() {
+			writeLine(&buf, "DTEND:"+formatTime(event.End))
+		}
+		writeLine(&buf, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Location != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(event.Location))
+		}
+		if event.URL != "" {
+			writeLine(&buf, "URL:"+event.URL)
+		}
+		if event.Cancelled {
+			writeLine(&buf, "STATUS:CANCELLED")
+		} else {
+			writeLine(&buf, "STATUS:CONFIRMED")
+		}
+		if !event.LastModified.IsZero() {
+			writeLine(&buf, "LAST-MODIFIED:"+formatTime(event.LastModified))
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// formatTime 统一使用 UTC 时间
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText 转义 TEXT 类型的值
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeLine 按 75 字节折行输出一行内容，不拆分 UTF-8 字符
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// 续行开头的空格占一个字节
+		limit = 74
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,107 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{`a\b`, `a\\b`},
+		{"a;b,c", `a\;b\,c`},
+		{"line1\nline2", `line1\nline2`},
+		{"line1\r\nline2", `line1\nline2`},
+		{"line1\rline2", `line1\nline2`},
+		{`\n`, `\\n`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:面试"},
+		{"exactly 75 bytes", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"ascii folded", "DESCRIPTION:" + strings.Repeat("a", 200)},
+		{"multibyte folded", "DESCRIPTION:" + strings.Repeat("面试地点", 30)},
+		{"mixed folded", "LOCATION:" + strings.Repeat("a面", 80)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeLine(&buf, tt.line)
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d has %d bytes, want at most 75", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+
+			// 去掉折行后应还原原始内容
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+			if len(tt.line) <= 75 && len(lines) != 1 {
+				t.Errorf("line of %d bytes was folded into %d lines", len(tt.line), len(lines))
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	start := time.Date(2026, 10, 20, 14, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	calendar := Calendar{
+		Name:   "招新面试",
+		Method: "PUBLISH",
+		Events: []Event{
+			{UID: "slot-1@example", Sequence: 2, Start: start, End: start.Add(30 * time.Minute), Summary: "Web, 一面", Cancelled: true},
+			{UID: "task-1@example", Start: start, Summary: "截止"},
+		},
+	}
+	out := string(calendar.Encode(start))
+
+	for _, want := range []string{
+		"METHOD:PUBLISH\r\n",
+		"X-WR-CALNAME:招新面试\r\n",
+		"DTSTAMP:20261020T060000Z\r\n",
+		"DTSTART:20261020T060000Z\r\n",
+		"DTEND:20261020T063000Z\r\n",
+		"SEQUENCE:2\r\n",
+		`SUMMARY:Web\, 一面` + "\r\n",
+		"STATUS:CANCELLED\r\n",
+		"STATUS:CONFIRMED\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Encode() missing %q", want)
+		}
+	}
+	if strings.Count(out, "DTEND:") != 1 {
+		t.Errorf("Encode() should omit DTEND for events without an end time")
+	}
+}
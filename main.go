@@ -4,11 +4,13 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"xdsec-join-2026/handlers"
 	"xdsec-join-2026/middleware"
 	"xdsec-join-2026/models"
+	"xdsec-join-2026/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -37,7 +39,45 @@ func main() {
 	sqlDB.SetConnMaxIdleTime(60 * time.Second) // 空闲连接最大存活时间
 
 	// 自动迁移
-	db.AutoMigrate(&models.User{}, &models.Application{}, &models.Announcement{}, &models.Task{}, &models.EmailCode{}, &models.EmailRateLimit{}, &models.Comment{})
+	db.AutoMigrate(&models.User{}, &models.Application{}, &models.Announcement{}, &models.Task{}, &models.EmailCode{}, &models.EmailRateLimit{}, &models.Comment{}, &models.Tag{}, &models.UserTag{}, &models.TagLog{}, &models.SavedView{}, &models.StatusTransition{}, &models.StatusChange{}, &models.DirectionStatus{}, &models.Round{}, &models.StatusDefinition{}, &models.Season{}, &models.DirectionWindow{}, &models.ApplicationExtension{}, &models.ApplicationRevision{}, &models.ApplicationDraft{}, &models.FormQuestion{}, &models.ApplicationAnswer{}, &models.Attachment{}, &models.Department{}, &models.Major{}, &models.DuplicateDismissal{}, &models.MergeRecord{}, &models.InterviewSlot{}, &models.SlotBooking{}, &models.CalendarToken{}, &models.Assignment{}, &models.ConflictOfInterest{}, &models.Rubric{}, &models.RubricCriterion{}, &models.Scorecard{}, &models.ScoreItem{}, &models.BlindReveal{}, &models.TaskReportVersion{})
+	handlers.SeedSeasons(db)
+	handlers.SeedDuplicateKeys(db)
+	handlers.SeedStatusDefinitions(db)
+	handlers.SeedStatusTransitions(db)
+	handlers.SeedTaskStatuses(db)
+	handlers.SeedReportVersions(db)
+	handlers.SeedAdmins(db, os.Getenv("adminEmails"))
+
+	// 学号格式
+	if pattern := os.Getenv("studentIdPattern"); pattern != "" {
+		if err := handlers.SetStudentIdPattern(pattern); err != nil {
+			log.Fatalf("学号格式配置错误: %v", err)
+		}
+	}
+
+	// 面试预约截止时间（小时）
+	slotBookingCutoff, slotCancelCutoff := 24*time.Hour, 12*time.Hour
+	if hours, err := strconv.Atoi(os.Getenv("slotBookingCutoffHours")); err == nil && hours >= 0 {
+		slotBookingCutoff = time.Duration(hours) * time.Hour
+	}
+	if hours, err := strconv.Atoi(os.Getenv("slotCancelCutoffHours")); err == nil && hours >= 0 {
+		slotCancelCutoff = time.Duration(hours) * time.Hour
+	}
+	handlers.SetBookingCutoffs(slotBookingCutoff, slotCancelCutoff)
+
+	// 附件存储
+	uploadDir := os.Getenv("uploadDir")
+	if uploadDir == "" {
+		uploadDir = "uploads"
+	}
+	store, err := storage.NewLocalStorage(uploadDir)
+	if err != nil {
+		log.Fatalf("初始化附件存储失败: %v", err)
+	}
+	maxUploadSize := int64(handlers.DefaultMaxUploadSize)
+	if size, err := strconv.ParseInt(os.Getenv("uploadMaxSizeMB"), 10, 64); err == nil && size > 0 {
+		maxUploadSize = size << 20
+	}
 
 	// 频率限制中间件（每分钟60次请求）
 	rateLimiter := middleware.NewIPRateLimiter(1, 60)
@@ -112,8 +152,32 @@ func main() {
 		usersRoute.PATCH("/me", handlers.AuthMiddleware(), handlers.UpdateProfile(db))
 		usersRoute.POST("/:id/role", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.SetUserRole(db))
 		usersRoute.POST("/:id/passed-directions", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.SetPassedDirections(db))
-		usersRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteUser(db))
-		usersRoute.DELETE("/me", handlers.AuthMiddleware(), handlers.DeleteSelf(db))
+		usersRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteUser(db, store))
+		usersRoute.POST("/:id/tags", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.AttachUserTag(db))
+		usersRoute.DELETE("/:id/tags/:tagId", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DetachUserTag(db))
+		usersRoute.GET("/:id/tag-logs", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetUserTagLogs(db))
+		usersRoute.DELETE("/me", handlers.AuthMiddleware(), handlers.DeleteSelf(db, store))
+		usersRoute.POST("/merge", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.MergeUsers(db))
+		usersRoute.GET("/merges", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.GetMergeRecords(db))
+		usersRoute.POST("/:id/reveal", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.RevealCandidate(db))
+	}
+
+	// 标签
+	tagsRoute := api.Group("/tags")
+	{
+		tagsRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetTags(db))
+		tagsRoute.POST("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.CreateTag(db))
+		tagsRoute.PATCH("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.UpdateTag(db))
+		tagsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteTag(db))
+	}
+
+	// 保存视图
+	viewsRoute := api.Group("/views")
+	{
+		viewsRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetViews(db))
+		viewsRoute.POST("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.CreateView(db))
+		viewsRoute.PATCH("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.UpdateView(db))
+		viewsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteView(db))
 	}
 
 	// 公告
@@ -131,10 +195,90 @@ func main() {
 	{
 		applicationsRoute.POST("", handlers.AuthMiddleware(), handlers.CreateApplication(db))
 		applicationsRoute.GET("/me", handlers.AuthMiddleware(), handlers.GetMyApplication(db))
+		applicationsRoute.GET("/window", handlers.AuthMiddleware(), handlers.GetApplicationWindow(db))
+		applicationsRoute.GET("/draft", handlers.AuthMiddleware(), handlers.GetApplicationDraft(db))
+		applicationsRoute.PUT("/draft", handlers.AuthMiddleware(), handlers.SaveApplicationDraft(db))
+		applicationsRoute.DELETE("/draft", handlers.AuthMiddleware(), handlers.DeleteApplicationDraft(db))
+		applicationsRoute.POST("/draft/submit", handlers.AuthMiddleware(), handlers.SubmitApplicationDraft(db))
+		applicationsRoute.POST("/attachments", handlers.AuthMiddleware(), handlers.UploadApplicationAttachment(db, store, maxUploadSize))
 		applicationsRoute.GET("/:userId", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetApplicationDetail(db))
+		applicationsRoute.POST("/bulk-status", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.BulkSetInterviewStatus(db))
 		applicationsRoute.POST("/:userId/status", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.SetInterviewStatus(db))
-		applicationsRoute.DELETE("/:userId", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteApplication(db))
-		applicationsRoute.DELETE("/me", handlers.AuthMiddleware(), handlers.DeleteSelfApplication(db))
+		applicationsRoute.GET("/:userId/status-history", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetStatusHistory(db))
+		applicationsRoute.GET("/:userId/revisions", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetApplicationRevisions(db))
+		applicationsRoute.GET("/:userId/revisions/diff", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetApplicationRevisionDiff(db))
+		applicationsRoute.POST("/:userId/extension", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GrantApplicationExtension(db))
+		applicationsRoute.DELETE("/:userId/extension", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.RevokeApplicationExtension(db))
+		applicationsRoute.DELETE("/:userId", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteApplication(db, store))
+		applicationsRoute.DELETE("/me", handlers.AuthMiddleware(), handlers.DeleteSelfApplication(db, store))
+	}
+
+	// 招新季
+	seasonsRoute := api.Group("/seasons")
+	{
+		seasonsRoute.GET("", rateLimiter.Middleware(), handlers.GetSeasons(db))
+		seasonsRoute.POST("", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.CreateSeason(db))
+		seasonsRoute.PATCH("/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateSeason(db))
+		seasonsRoute.POST("/:id/activate", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.ActivateSeason(db))
+		seasonsRoute.PUT("/:id/windows/:direction", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.SetDirectionWindow(db))
+		seasonsRoute.DELETE("/:id/windows/:direction", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DeleteDirectionWindow(db))
+	}
+
+	// 学院与专业目录
+	catalogRoute := api.Group("/catalog")
+	{
+		catalogRoute.GET("/departments", rateLimiter.Middleware(), handlers.GetDepartments(db))
+		catalogRoute.POST("/departments", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.CreateDepartment(db))
+		catalogRoute.PATCH("/departments/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateDepartment(db))
+		catalogRoute.DELETE("/departments/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DeleteDepartment(db))
+		catalogRoute.POST("/departments/:id/majors", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.CreateMajor(db))
+		catalogRoute.PATCH("/majors/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateMajor(db))
+		catalogRoute.DELETE("/majors/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DeleteMajor(db))
+	}
+
+	// 重复申请检测
+	duplicatesRoute := api.Group("/duplicates")
+	{
+		duplicatesRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetDuplicates(db))
+		duplicatesRoute.POST("/dismiss", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DismissDuplicates(db))
+	}
+
+	// 申请表自定义问题
+	formRoute := api.Group("/form")
+	{
+		formRoute.GET("/questions", handlers.AuthMiddleware(), handlers.GetFormQuestions(db))
+		formRoute.POST("/questions", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.CreateFormQuestion(db))
+		formRoute.PATCH("/questions/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateFormQuestion(db))
+		formRoute.DELETE("/questions/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DeleteFormQuestion(db))
+	}
+
+	// 面试轮次与状态配置
+	statusesRoute := api.Group("/statuses")
+	{
+		statusesRoute.GET("", rateLimiter.Middleware(), handlers.GetStatuses(db))
+		statusesRoute.POST("", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.CreateStatus(db))
+		statusesRoute.PATCH("/:key", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateStatus(db))
+		statusesRoute.DELETE("/:key", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DeleteStatus(db))
+	}
+	roundsRoute := api.Group("/rounds")
+	{
+		roundsRoute.POST("", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.CreateRound(db))
+		roundsRoute.PATCH("/:number", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateRound(db))
+		roundsRoute.DELETE("/:number", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DeleteRound(db))
+	}
+
+	// 状态流转配置
+	transitionsRoute := api.Group("/status-transitions")
+	{
+		transitionsRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetStatusTransitions(db))
+		transitionsRoute.PUT("", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateStatusTransitions(db))
+	}
+
+	// 招新看板
+	pipelineRoute := api.Group("/pipeline")
+	{
+		pipelineRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetPipeline(db))
+		pipelineRoute.POST("/move", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.MovePipelineCard(db))
 	}
 
 	// 面试任务
@@ -144,7 +288,79 @@ func main() {
 		tasksRoute.POST("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.CreateTask(db))
 		tasksRoute.PATCH("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.UpdateTask(db))
 		tasksRoute.POST("/:id/report", handlers.AuthMiddleware(), handlers.SubmitTaskReport(db))
-		tasksRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteTask(db))
+		tasksRoute.POST("/:id/attachments", handlers.AuthMiddleware(), handlers.UploadTaskAttachment(db, store, maxUploadSize))
+		tasksRoute.POST("/:id/review", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.ReviewTask(db))
+		tasksRoute.POST("/:id/extend", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.ExtendTaskDeadline(db))
+		tasksRoute.GET("/:id/versions", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetTaskReportVersions(db))
+		tasksRoute.GET("/:id/versions/diff", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetTaskReportDiff(db))
+		tasksRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteTask(db, store))
+	}
+
+	// 面试时间段与预约
+	slotsRoute := api.Group("/slots")
+	{
+		slotsRoute.GET("", handlers.AuthMiddleware(), handlers.GetSlots(db))
+		slotsRoute.POST("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.CreateSlot(db))
+		slotsRoute.GET("/mine", handlers.AuthMiddleware(), handlers.GetMySchedule(db))
+		slotsRoute.PATCH("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.UpdateSlot(db))
+		slotsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.CancelSlot(db))
+		slotsRoute.GET("/:id/bookings", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetSlotBookings(db))
+		slotsRoute.POST("/:id/book", handlers.AuthMiddleware(), handlers.BookSlot(db))
+		slotsRoute.DELETE("/:id/book", handlers.AuthMiddleware(), handlers.CancelBooking(db))
+		slotsRoute.POST("/:id/reschedule", handlers.AuthMiddleware(), handlers.RescheduleBooking(db))
+	}
+
+	// 利益冲突声明
+	conflictsRoute := api.Group("/conflicts")
+	{
+		conflictsRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetConflicts(db))
+		conflictsRoute.POST("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeclareConflict(db))
+		conflictsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteConflict(db))
+	}
+
+	// 面试官分配
+	assignmentsRoute := api.Group("/assignments")
+	{
+		assignmentsRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetAssignments(db))
+		assignmentsRoute.PUT("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.AssignInterviewer(db))
+		assignmentsRoute.GET("/load", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetAssignmentLoad(db))
+		assignmentsRoute.POST("/auto", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.AutoAssign(db))
+		assignmentsRoute.PUT("/capacity/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.SetAssignmentCapacity(db))
+		assignmentsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteAssignment(db))
+	}
+
+	// 评分标准
+	rubricsRoute := api.Group("/rubrics")
+	{
+		rubricsRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetRubrics(db))
+		rubricsRoute.POST("", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.CreateRubric(db))
+		rubricsRoute.PUT("/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.UpdateRubric(db))
+		rubricsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireAdmin(db), handlers.DeleteRubric(db))
+	}
+
+	// 评分表
+	scorecardsRoute := api.Group("/scorecards")
+	{
+		scorecardsRoute.GET("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetScorecards(db))
+		scorecardsRoute.PUT("", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.SubmitScorecard(db))
+		scorecardsRoute.GET("/distribution", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetScoreDistribution(db))
+		scorecardsRoute.GET("/rankings", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.GetRankings(db))
+		scorecardsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.RequireInterviewer(), handlers.DeleteScorecard(db))
+	}
+
+	// 日历订阅
+	calendarRoute := api.Group("/calendar")
+	{
+		calendarRoute.GET("/token", handlers.AuthMiddleware(), handlers.GetCalendarToken(db))
+		calendarRoute.POST("/token/reset", handlers.AuthMiddleware(), handlers.ResetCalendarToken(db))
+		calendarRoute.GET("/:file", rateLimiter.Middleware(), handlers.GetCalendarFeed(db))
+	}
+
+	// 附件
+	attachmentsRoute := api.Group("/attachments")
+	{
+		attachmentsRoute.GET("/:id", handlers.AuthMiddleware(), handlers.DownloadAttachment(db, store))
+		attachmentsRoute.DELETE("/:id", handlers.AuthMiddleware(), handlers.DeleteAttachment(db, store))
 	}
 
 	// 评论
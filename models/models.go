@@ -12,10 +12,12 @@ type User struct {
 	Nickname           *string      `gorm:"column:nickname" json:"nickname"`
 	Signature          string       `gorm:"column:signature" json:"signature"`
 	Role               string       `gorm:"type:enum('interviewee', 'interviewer');default:'interviewee'" json:"role"`
-	Status             string       `gorm:"type:enum('r1_pending', 'r1_passed', 'r2_pending', 'r2_passed', 'rejected', 'offer');default:'r1_pending'" json:"status"`
+	IsAdmin            bool         `gorm:"column:is_admin;default:false" json:"isAdmin"`
+	Status             string       `gorm:"column:status;size:32;not null" json:"status"`
 	Directions         string       `gorm:"type:json" json:"directions"`
 	PassedDirections   string       `gorm:"type:json" json:"passedDirections"`
 	PassedDirectionsBy string       `gorm:"type:json" json:"passedDirectionsBy"`
+	AssignmentCapacity int          `gorm:"column:assignment_capacity;default:10" json:"assignmentCapacity"`
 	Application        *Application `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"application,omitempty"`
 	CreatedAt          time.Time    `json:"createdAt"`
 	UpdatedAt          time.Time    `json:"updatedAt"`
@@ -23,18 +25,23 @@ type User struct {
 }
 
 type Application struct {
-	ID         uint      `gorm:"primarykey" json:"-"`
-	RealName   string    `gorm:"column:real_name;not null" json:"realName"`
-	Phone      string    `gorm:"column:phone;not null" json:"phone"`
-	Gender     string    `gorm:"type:enum('male', 'female');not null" json:"gender"`
-	Department string    `gorm:"column:department;not null" json:"department"`
-	Major      string    `gorm:"column:major;not null" json:"major"`
-	StudentId  string    `gorm:"column:student_id;not null" json:"studentId"`
-	Directions string    `gorm:"type:json" json:"directions"`
-	Resume     string    `gorm:"column:resume;type:text;not null" json:"resume"`
-	UserID     uuid.UUID `gorm:"type:char(36);uniqueIndex" json:"-"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	ID             uint      `gorm:"primarykey" json:"-"`
+	RealName       string    `gorm:"column:real_name;not null" json:"realName"`
+	Phone          string    `gorm:"column:phone;not null" json:"phone"`
+	Gender         string    `gorm:"type:enum('male', 'female');not null" json:"gender"`
+	Department     string    `gorm:"column:department;not null" json:"department"`
+	Major          string    `gorm:"column:major;not null" json:"major"`
+	StudentId      string    `gorm:"column:student_id;not null" json:"studentId"`
+	EnrollmentYear int       `gorm:"column:enrollment_year;default:0" json:"enrollmentYear"`
+	PhoneKey       string    `gorm:"column:phone_key;size:20;index" json:"-"`
+	StudentIdKey   string    `gorm:"column:student_id_key;size:32;index" json:"-"`
+	NameKey        string    `gorm:"column:name_key;size:64;index" json:"-"`
+	Directions     string    `gorm:"type:json" json:"directions"`
+	Resume         string    `gorm:"column:resume;type:text;not null" json:"resume"`
+	UserID         uuid.UUID `gorm:"type:char(36);uniqueIndex:idx_application_user_season" json:"-"`
+	SeasonID       uuid.UUID `gorm:"column:season_id;type:char(36);uniqueIndex:idx_application_user_season" json:"seasonId"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
 
 type Announcement struct {
@@ -45,19 +52,31 @@ type Announcement struct {
 	AuthorId        uuid.UUID `gorm:"column:author_id" json:"authorId"`
 	Visibility      string    `gorm:"type:enum('public','all','interviewer','status');default:'public'" json:"visibility"`
 	AllowedStatuses string    `gorm:"type:json" json:"allowedStatuses"`
+	SeasonID        uuid.UUID `gorm:"column:season_id;type:char(36);index" json:"seasonId"`
 	CreatedAt       time.Time `json:"createdAt"`
 	UpdatedAt       time.Time `json:"updatedAt"`
 }
 
 type Task struct {
-	UUID         uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description"`
-	TargetUserId uuid.UUID `gorm:"column:target_user_id" json:"targetUserId"`
-	AssignedBy   uuid.UUID `gorm:"column:assigned_by" json:"assignedBy"`
-	Report       string    `json:"report"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	UUID          uuid.UUID  `gorm:"type:char(36);primarykey" json:"id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	TargetUserId  uuid.UUID  `gorm:"column:target_user_id" json:"targetUserId"`
+	AssignedBy    uuid.UUID  `gorm:"column:assigned_by" json:"assignedBy"`
+	Report        string     `json:"report"`
+	ReviewedBy    *uuid.UUID `gorm:"column:reviewed_by;type:char(36)" json:"reviewedBy"`
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at" json:"reviewedAt"`
+	SeasonID      uuid.UUID  `gorm:"column:season_id;type:char(36);index" json:"seasonId"`
+	DueAt         *time.Time `gorm:"column:due_at" json:"dueAt"`
+	Status        string     `gorm:"column:status;type:enum('assigned','submitted','reviewed','accepted','needs_revision');default:'assigned';index" json:"status"`
+	LatePolicy    string     `gorm:"column:late_policy;type:enum('flag','reject');default:'flag'" json:"latePolicy"`
+	Late          bool       `gorm:"column:late;default:false" json:"late"`
+	SubmittedAt   *time.Time `gorm:"column:submitted_at" json:"submittedAt"`
+	ReviewNote    string     `gorm:"column:review_note;size:1000" json:"reviewNote"`
+	OriginalDueAt *time.Time `gorm:"column:original_due_at" json:"originalDueAt"`
+	ExtendedBy    *uuid.UUID `gorm:"column:extended_by;type:char(36)" json:"extendedBy"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
 
 type EmailCode struct {
@@ -85,3 +104,335 @@ type Comment struct {
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
 }
+
+type Tag struct {
+	UUID      uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	Name      string    `gorm:"column:name;size:32;uniqueIndex;not null" json:"name"`
+	Color     string    `gorm:"column:color;size:7;not null" json:"color"`
+	CreatedBy uuid.UUID `gorm:"column:created_by;type:char(36)" json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type UserTag struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	UserID    uuid.UUID `gorm:"column:user_id;type:char(36);uniqueIndex:idx_user_tag;not null" json:"userId"`
+	TagID     uuid.UUID `gorm:"column:tag_id;type:char(36);uniqueIndex:idx_user_tag;index;not null" json:"tagId"`
+	TaggedBy  uuid.UUID `gorm:"column:tagged_by;type:char(36)" json:"taggedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TagLog struct {
+	UUID      uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	UserID    uuid.UUID `gorm:"column:user_id;type:char(36);index;not null" json:"userId"`
+	TagID     uuid.UUID `gorm:"column:tag_id;type:char(36)" json:"tagId"`
+	TagName   string    `gorm:"column:tag_name" json:"tagName"`
+	Action    string    `gorm:"type:enum('attach', 'detach');not null" json:"action"`
+	ActorID   uuid.UUID `gorm:"column:actor_id;type:char(36)" json:"actorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type SavedView struct {
+	UUID      uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	Name      string    `gorm:"column:name;size:50;not null" json:"name"`
+	OwnerID   uuid.UUID `gorm:"column:owner_id;type:char(36);index;not null" json:"ownerId"`
+	Shared    bool      `gorm:"column:shared;default:false" json:"shared"`
+	Filters   string    `gorm:"type:json" json:"filters"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type StatusTransition struct {
+	ID         uint      `gorm:"primarykey" json:"-"`
+	FromStatus string    `gorm:"column:from_status;size:32;uniqueIndex:idx_status_transition;not null" json:"from"`
+	ToStatus   string    `gorm:"column:to_status;size:32;uniqueIndex:idx_status_transition;not null" json:"to"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type StatusChange struct {
+	UUID       uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	UserID     uuid.UUID `gorm:"column:user_id;type:char(36);index;not null" json:"userId"`
+	Direction  string    `gorm:"column:direction;size:16" json:"direction"`
+	FromStatus string    `gorm:"column:from_status;size:32" json:"from"`
+	ToStatus   string    `gorm:"column:to_status;size:32;not null" json:"to"`
+	Reason     string    `gorm:"column:reason;size:200" json:"reason"`
+	ActorID    uuid.UUID `gorm:"column:actor_id;type:char(36)" json:"actorId"`
+	Forced     bool      `gorm:"column:forced;default:false" json:"forced"`
+	SeasonID   uuid.UUID `gorm:"column:season_id;type:char(36);index" json:"seasonId"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type DirectionStatus struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	UserID    uuid.UUID `gorm:"column:user_id;type:char(36);uniqueIndex:idx_user_direction_season;not null" json:"userId"`
+	Direction string    `gorm:"column:direction;size:16;uniqueIndex:idx_user_direction_season;not null" json:"direction"`
+	SeasonID  uuid.UUID `gorm:"column:season_id;type:char(36);uniqueIndex:idx_user_direction_season" json:"seasonId"`
+	Status    string    `gorm:"column:status;size:32;not null" json:"status"`
+	Passed    bool      `gorm:"column:passed;default:false" json:"passed"`
+	UpdatedBy uuid.UUID `gorm:"column:updated_by;type:char(36)" json:"updatedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Round struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Number    int       `gorm:"column:number;uniqueIndex;not null" json:"number"`
+	Name      string    `gorm:"column:name;size:50;not null" json:"name"`
+	Blind     bool      `gorm:"column:blind;default:false" json:"blind"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type StatusDefinition struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	Key       string    `gorm:"column:status_key;size:32;uniqueIndex;not null" json:"key"`
+	Label     string    `gorm:"column:label;size:50;not null" json:"label"`
+	Round     int       `gorm:"column:round;default:0" json:"round"`
+	SortOrder int       `gorm:"column:sort_order;default:0" json:"sortOrder"`
+	Terminal  bool      `gorm:"column:is_terminal;default:false" json:"terminal"`
+	Initial   bool      `gorm:"column:is_initial;default:false" json:"initial"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Season struct {
+	UUID      uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	Name      string    `gorm:"column:name;size:50;uniqueIndex;not null" json:"name"`
+	OpensAt   time.Time `gorm:"column:opens_at" json:"opensAt"`
+	ClosesAt  time.Time `gorm:"column:closes_at" json:"closesAt"`
+	Active    bool      `gorm:"column:active;default:false" json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type DirectionWindow struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	SeasonID  uuid.UUID `gorm:"column:season_id;type:char(36);uniqueIndex:idx_season_direction;not null" json:"seasonId"`
+	Direction string    `gorm:"column:direction;size:16;uniqueIndex:idx_season_direction;not null" json:"direction"`
+	OpensAt   time.Time `gorm:"column:opens_at" json:"opensAt"`
+	ClosesAt  time.Time `gorm:"column:closes_at" json:"closesAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ApplicationExtension struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	SeasonID  uuid.UUID `gorm:"column:season_id;type:char(36);uniqueIndex:idx_extension_season_user;not null" json:"seasonId"`
+	UserID    uuid.UUID `gorm:"column:user_id;type:char(36);uniqueIndex:idx_extension_season_user;not null" json:"userId"`
+	Until     time.Time `gorm:"column:until" json:"until"`
+	Reason    string    `gorm:"column:reason;size:200" json:"reason"`
+	GrantedBy uuid.UUID `gorm:"column:granted_by;type:char(36)" json:"grantedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ApplicationRevision struct {
+	UUID          uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	ApplicationID uint      `gorm:"column:application_id;uniqueIndex:idx_application_version;not null" json:"-"`
+	UserID        uuid.UUID `gorm:"column:user_id;type:char(36);index;not null" json:"userId"`
+	SeasonID      uuid.UUID `gorm:"column:season_id;type:char(36);index" json:"seasonId"`
+	Version       int       `gorm:"column:version;uniqueIndex:idx_application_version;not null" json:"version"`
+	Snapshot      string    `gorm:"column:snapshot;type:json" json:"snapshot"`
+	AuthorID      uuid.UUID `gorm:"column:author_id;type:char(36)" json:"authorId"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type ApplicationDraft struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	UserID    uuid.UUID `gorm:"column:user_id;type:char(36);uniqueIndex:idx_draft_user_season;not null" json:"-"`
+	SeasonID  uuid.UUID `gorm:"column:season_id;type:char(36);uniqueIndex:idx_draft_user_season;not null" json:"seasonId"`
+	Data      string    `gorm:"column:data;type:json" json:"data"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type FormQuestion struct {
+	UUID        uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	SeasonID    uuid.UUID `gorm:"column:season_id;type:char(36);index;not null" json:"seasonId"`
+	Title       string    `gorm:"column:title;size:100;not null" json:"title"`
+	Description string    `gorm:"column:description;size:500" json:"description"`
+	Type        string    `gorm:"type:enum('text','textarea','single','multiple','url','number');default:'text'" json:"type"`
+	Required    bool      `gorm:"column:required;default:false" json:"required"`
+	MaxLength   int       `gorm:"column:max_length;default:0" json:"maxLength"`
+	Options     string    `gorm:"column:options;type:json" json:"options"`
+	Directions  string    `gorm:"column:directions;type:json" json:"directions"`
+	SortOrder   int       `gorm:"column:sort_order;default:0" json:"sortOrder"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type ApplicationAnswer struct {
+	ID            uint      `gorm:"primarykey" json:"-"`
+	ApplicationID uint      `gorm:"column:application_id;uniqueIndex:idx_application_question;not null" json:"-"`
+	QuestionID    uuid.UUID `gorm:"column:question_id;type:char(36);uniqueIndex:idx_application_question;not null" json:"questionId"`
+	Value         string    `gorm:"column:value;type:text" json:"value"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type Attachment struct {
+	UUID          uuid.UUID  `gorm:"type:char(36);primarykey" json:"id"`
+	OwnerID       uuid.UUID  `gorm:"column:owner_id;type:char(36);index;not null" json:"ownerId"`
+	ApplicationID *uint      `gorm:"column:application_id;index" json:"-"`
+	TaskID        *uuid.UUID `gorm:"column:task_id;type:char(36);index" json:"taskId"`
+	FileName      string     `gorm:"column:file_name;size:255;not null" json:"fileName"`
+	ContentType   string     `gorm:"column:content_type;size:100;not null" json:"contentType"`
+	Size          int64      `gorm:"column:size;not null" json:"size"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type Department struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"column:name;size:20;uniqueIndex;not null" json:"name"`
+	SortOrder int       `gorm:"column:sort_order;default:0" json:"sortOrder"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Major struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	DepartmentID uint      `gorm:"column:department_id;uniqueIndex:idx_department_major;not null" json:"departmentId"`
+	Name         string    `gorm:"column:name;size:20;uniqueIndex:idx_department_major;not null" json:"name"`
+	SortOrder    int       `gorm:"column:sort_order;default:0" json:"sortOrder"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type DuplicateDismissal struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	UserA       uuid.UUID `gorm:"column:user_a;type:char(36);uniqueIndex:idx_dismissal_pair;not null" json:"userA"`
+	UserB       uuid.UUID `gorm:"column:user_b;type:char(36);uniqueIndex:idx_dismissal_pair;not null" json:"userB"`
+	DismissedBy uuid.UUID `gorm:"column:dismissed_by;type:char(36)" json:"dismissedBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type MergeRecord struct {
+	UUID           uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	SurvivorID     uuid.UUID `gorm:"column:survivor_id;type:char(36);index;not null" json:"survivorId"`
+	MergedID       uuid.UUID `gorm:"column:merged_id;type:char(36);index;not null" json:"mergedId"`
+	MergedEmail    string    `gorm:"column:merged_email" json:"mergedEmail"`
+	MergedNickname string    `gorm:"column:merged_nickname" json:"mergedNickname"`
+	MergedBy       uuid.UUID `gorm:"column:merged_by;type:char(36)" json:"mergedBy"`
+	Details        string    `gorm:"column:details;type:json" json:"details"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type InterviewSlot struct {
+	UUID             uuid.UUID  `gorm:"type:char(36);primarykey" json:"id"`
+	SeasonID         uuid.UUID  `gorm:"column:season_id;type:char(36);index;not null" json:"seasonId"`
+	InterviewerID    uuid.UUID  `gorm:"column:interviewer_id;type:char(36);index;not null" json:"interviewerId"`
+	Direction        string     `gorm:"column:direction;size:32;not null" json:"direction"`
+	StartsAt         time.Time  `gorm:"column:starts_at;index;not null" json:"startsAt"`
+	Duration         int        `gorm:"column:duration;not null" json:"duration"`
+	Location         string     `gorm:"column:location;size:200" json:"location"`
+	OnlineLink       string     `gorm:"column:online_link;size:500" json:"onlineLink"`
+	Capacity         int        `gorm:"column:capacity;not null" json:"capacity"`
+	BookedCount      int        `gorm:"column:booked_count;default:0;not null" json:"bookedCount"`
+	EligibleStatuses string     `gorm:"column:eligible_statuses;type:json" json:"eligibleStatuses"`
+	Note             string     `gorm:"column:note;size:500" json:"note"`
+	Sequence         int        `gorm:"column:sequence;default:0;not null" json:"sequence"`
+	CancelledAt      *time.Time `gorm:"column:cancelled_at" json:"cancelledAt"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+type SlotBooking struct {
+	UUID        uuid.UUID  `gorm:"type:char(36);primarykey" json:"id"`
+	SlotID      uuid.UUID  `gorm:"column:slot_id;type:char(36);index;not null" json:"slotId"`
+	UserID      uuid.UUID  `gorm:"column:user_id;type:char(36);index;not null" json:"userId"`
+	SeasonID    uuid.UUID  `gorm:"column:season_id;type:char(36);index;not null" json:"seasonId"`
+	Direction   string     `gorm:"column:direction;size:32;not null" json:"direction"`
+	Status      string     `gorm:"type:enum('booked','cancelled');default:'booked'" json:"status"`
+	CancelledAt *time.Time `gorm:"column:cancelled_at" json:"cancelledAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type CalendarToken struct {
+	UserID    uuid.UUID `gorm:"column:user_id;type:char(36);primarykey" json:"userId"`
+	Token     string    `gorm:"column:token;size:64;uniqueIndex;not null" json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Assignment struct {
+	UUID          uuid.UUID  `gorm:"type:char(36);primarykey" json:"id"`
+	SeasonID      uuid.UUID  `gorm:"column:season_id;type:char(36);uniqueIndex:idx_assignment_candidate;not null" json:"seasonId"`
+	UserID        uuid.UUID  `gorm:"column:user_id;type:char(36);uniqueIndex:idx_assignment_candidate;not null" json:"userId"`
+	Direction     string     `gorm:"column:direction;size:16;uniqueIndex:idx_assignment_candidate;not null" json:"direction"`
+	InterviewerID uuid.UUID  `gorm:"column:interviewer_id;type:char(36);index;not null" json:"interviewerId"`
+	AssignedBy    *uuid.UUID `gorm:"column:assigned_by;type:char(36)" json:"assignedBy"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+type ConflictOfInterest struct {
+	UUID          uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	InterviewerID uuid.UUID `gorm:"column:interviewer_id;type:char(36);uniqueIndex:idx_conflict_pair;not null" json:"interviewerId"`
+	UserID        uuid.UUID `gorm:"column:user_id;type:char(36);uniqueIndex:idx_conflict_pair;index;not null" json:"userId"`
+	Reason        string    `gorm:"column:reason;size:200" json:"reason"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type Rubric struct {
+	UUID      uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	SeasonID  uuid.UUID `gorm:"column:season_id;type:char(36);uniqueIndex:idx_rubric_scope;not null" json:"seasonId"`
+	Direction string    `gorm:"column:direction;size:16;uniqueIndex:idx_rubric_scope;not null" json:"direction"`
+	Round     int       `gorm:"column:round;uniqueIndex:idx_rubric_scope;not null" json:"round"`
+	Name      string    `gorm:"column:name;size:50;not null" json:"name"`
+	ScaleMin  int       `gorm:"column:scale_min;default:0" json:"scaleMin"`
+	ScaleMax  int       `gorm:"column:scale_max;default:10" json:"scaleMax"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type RubricCriterion struct {
+	UUID        uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	RubricID    uuid.UUID `gorm:"column:rubric_id;type:char(36);index;not null" json:"rubricId"`
+	Name        string    `gorm:"column:name;size:50;not null" json:"name"`
+	Description string    `gorm:"column:description;size:500" json:"description"`
+	Weight      float64   `gorm:"column:weight;not null" json:"weight"`
+	SortOrder   int       `gorm:"column:sort_order;default:0" json:"sortOrder"`
+}
+
+type Scorecard struct {
+	UUID          uuid.UUID `gorm:"type:char(36);primarykey" json:"id"`
+	RubricID      uuid.UUID `gorm:"column:rubric_id;type:char(36);uniqueIndex:idx_scorecard_owner;not null" json:"rubricId"`
+	UserID        uuid.UUID `gorm:"column:user_id;type:char(36);uniqueIndex:idx_scorecard_owner;index;not null" json:"userId"`
+	InterviewerID uuid.UUID `gorm:"column:interviewer_id;type:char(36);uniqueIndex:idx_scorecard_owner;not null" json:"interviewerId"`
+	SeasonID      uuid.UUID `gorm:"column:season_id;type:char(36);index;not null" json:"seasonId"`
+	Direction     string    `gorm:"column:direction;size:16;not null" json:"direction"`
+	Round         int       `gorm:"column:round;not null" json:"round"`
+	Total         float64   `gorm:"column:total;not null" json:"total"`
+	Comment       string    `gorm:"column:comment;size:1000" json:"comment"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type ScoreItem struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	ScorecardID uuid.UUID `gorm:"column:scorecard_id;type:char(36);uniqueIndex:idx_score_item;not null" json:"scorecardId"`
+	CriterionID uuid.UUID `gorm:"column:criterion_id;type:char(36);uniqueIndex:idx_score_item;not null" json:"criterionId"`
+	Score       int       `gorm:"column:score;not null" json:"score"`
+}
+
+type BlindReveal struct {
+	ID         uint      `gorm:"primarykey" json:"-"`
+	SeasonID   uuid.UUID `gorm:"column:season_id;type:char(36);uniqueIndex:idx_blind_reveal;not null" json:"seasonId"`
+	UserID     uuid.UUID `gorm:"column:user_id;type:char(36);uniqueIndex:idx_blind_reveal;not null" json:"userId"`
+	Round      int       `gorm:"column:round;uniqueIndex:idx_blind_reveal;not null" json:"round"`
+	RevealedBy uuid.UUID `gorm:"column:revealed_by;type:char(36)" json:"revealedBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type TaskReportVersion struct {
+	UUID         uuid.UUID  `gorm:"type:char(36);primarykey" json:"id"`
+	TaskID       uuid.UUID  `gorm:"column:task_id;type:char(36);uniqueIndex:idx_task_version;not null" json:"taskId"`
+	UserID       uuid.UUID  `gorm:"column:user_id;type:char(36);index;not null" json:"userId"`
+	Version      int        `gorm:"column:version;uniqueIndex:idx_task_version;not null" json:"version"`
+	Report       string     `gorm:"column:report;type:text;not null" json:"report"`
+	Late         bool       `gorm:"column:late;default:false" json:"late"`
+	ReviewStatus string     `gorm:"column:review_status;size:20" json:"reviewStatus"`
+	ReviewedBy   *uuid.UUID `gorm:"column:reviewed_by;type:char(36)" json:"reviewedBy"`
+	ReviewedAt   *time.Time `gorm:"column:reviewed_at" json:"reviewedAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
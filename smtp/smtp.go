@@ -1,20 +1,19 @@
 package smtp
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"os"
 )
 
 // SendEmailCode 发送邮箱验证码
 func SendEmailCode(to, code, purpose string) error {
-	smtpHost := os.Getenv("smtpHost")
-	smtpPort := os.Getenv("smtpPort")
-	smtpUser := os.Getenv("smtpUser")
-	smtpPassword := os.Getenv("smtpPassword")
-
-	from := os.Getenv("smtpUser")
-
 	// 根据不同用途生成不同的邮件内容
 	var subject, body string
 	switch purpose {
@@ -36,15 +35,72 @@ func SendEmailCode(to, code, purpose string) error {
 		"\r\n" +
 		body + "\r\n")
 
+	return sendMail(to, message)
+}
+
+// SendWithCalendar 发送附带 iCalendar 文件的邮件，日历应用可以直接导入
+func SendWithCalendar(to, subject, body, fileName string, calendar []byte) error {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	textHeader := textproto.MIMEHeader{}
+	textHeader.Set("Content-Type", "text/plain; charset=UTF-8")
+	textHeader.Set("Content-Transfer-Encoding", "base64")
+	part, err := writer.CreatePart(textHeader)
+	if err != nil {
+		return err
+	}
+	writeBase64(part, []byte(body))
+
+	calendarHeader := textproto.MIMEHeader{}
+	calendarHeader.Set("Content-Type", `text/calendar; charset=UTF-8; method=PUBLISH; name="`+fileName+`"`)
+	calendarHeader.Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	calendarHeader.Set("Content-Transfer-Encoding", "base64")
+	part, err = writer.CreatePart(calendarHeader)
+	if err != nil {
+		return err
+	}
+	writeBase64(part, calendar)
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	message := []byte("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=" + writer.Boundary() + "\r\n" +
+		"\r\n")
+	message = append(message, buf.Bytes()...)
+
+	return sendMail(to, message)
+}
+
+// writeBase64 按每行 76 个字符写入 base64 编码的内容
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
+
+// sendMail 使用配置的 SMTP 服务器发送邮件
+func sendMail(to string, message []byte) error {
+	smtpHost := os.Getenv("smtpHost")
+	smtpPort := os.Getenv("smtpPort")
+	smtpUser := os.Getenv("smtpUser")
+	smtpPassword := os.Getenv("smtpPassword")
+
+	from := os.Getenv("smtpUser")
+
 	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
 
-	err := smtp.SendMail(
+	return smtp.SendMail(
 		smtpHost+":"+smtpPort,
 		auth,
 		from,
 		[]string{to},
 		message,
 	)
-
-	return err
 }
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrInvalidKey 文件标识不合法
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage 附件存储后端
+type Storage interface {
+	// Save 保存文件，标识已存在时覆盖
+	Save(key string, reader io.Reader) error
+	// Open 打开文件用于读取
+	Open(key string) (io.ReadCloser, error)
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(key string) error
+}
+
+// LocalStorage 本地文件系统存储
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage 创建本地文件系统存储，目录不存在时自动创建
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// path 计算文件路径，标识只能是单层文件名，防止越出存储目录
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || filepath.Base(key) != key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, key), nil
+}
+
+// Save 先写入临时文件再重命名，避免读到写了一半的文件
+func (s *LocalStorage) Save(key string, reader io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open 打开文件
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete 删除文件
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package utils
+
+import "strings"
+
+// DiffLine 行级差异中的一行
+type DiffLine struct {
+	// Op 为 equal、insert 或 delete
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxDiffLines 去掉相同的首尾后每侧参与比较的最大行数，超出时整段视为删除后插入
+const maxDiffLines = 5000
+
+// DiffLines 基于最长公共子序列计算两段文本的行级差异
+// 使用 Hirschberg 算法，内存占用与行数成线性关系
+func DiffLines(from, to string) []DiffLine {
+	a := splitLines(from)
+	b := splitLines(to)
+	result := make([]DiffLine, 0, len(a)+len(b))
+
+	// 相同的首尾直接输出，只比较中间变化的部分
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, line := range a[:prefix] {
+		result = append(result, DiffLine{Op: "equal", Text: line})
+	}
+
+	middleA := a[prefix : len(a)-suffix]
+	middleB := b[prefix : len(b)-suffix]
+	if len(middleA) > maxDiffLines || len(middleB) > maxDiffLines {
+		result = appendLines(result, "delete", middleA)
+		result = appendLines(result, "insert", middleB)
+	} else {
+		result = diffRange(result, middleA, middleB)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, DiffLine{Op: "equal", Text: line})
+	}
+	return result
+}
+
+// diffRange 递归地将 a 从中间一分为二，找到 b 中使两半公共子序列之和最大的切分点
+func diffRange(result []DiffLine, a, b []string) []DiffLine {
+	if len(a) == 0 {
+		return appendLines(result, "insert", b)
+	}
+	if len(b) == 0 {
+		return appendLines(result, "delete", a)
+	}
+	if len(a) == 1 {
+		for k, line := range b {
+			if line == a[0] {
+				result = appendLines(result, "insert", b[:k])
+				result = append(result, DiffLine{Op: "equal", Text: line})
+				return appendLines(result, "insert", b[k+1:])
+			}
+		}
+		result = append(result, DiffLine{Op: "delete", Text: a[0]})
+		return appendLines(result, "insert", b)
+	}
+
+	mid := len(a) / 2
+	forward := lcsPrefixRow(a[:mid], b)
+	backward := lcsSuffixRow(a[mid:], b)
+	split := 0
+	for k := range forward {
+		if forward[k]+backward[k] > forward[split]+backward[split] {
+			split = k
+		}
+	}
+	result = diffRange(result, a[:mid], b[:split])
+	return diffRange(result, a[mid:], b[split:])
+}
+
+// lcsPrefixRow row[k] 为 a 与 b[:k] 的最长公共子序列长度
+func lcsPrefixRow(a, b []string) []int {
+	row := make([]int, len(b)+1)
+	for i := range a {
+		diagonal := 0
+		for j := range b {
+			above := row[j+1]
+			if a[i] == b[j] {
+				row[j+1] = diagonal + 1
+			} else {
+				row[j+1] = max(above, row[j])
+			}
+			diagonal = above
+		}
+	}
+	return row
+}
+
+// lcsSuffixRow row[k] 为 a 与 b[k:] 的最长公共子序列长度
+func lcsSuffixRow(a, b []string) []int {
+	row := make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		diagonal := 0
+		for j := len(b) - 1; j >= 0; j-- {
+			below := row[j]
+			if a[i] == b[j] {
+				row[j] = diagonal + 1
+			} else {
+				row[j] = max(below, row[j+1])
+			}
+			diagonal = below
+		}
+	}
+	return row
+}
+
+// appendLines 将多行以同一种操作追加到结果中
+func appendLines(result []DiffLine, op string, lines []string) []DiffLine {
+	for _, line := range lines {
+		result = append(result, DiffLine{Op: op, Text: line})
+	}
+	return result
+}
+
+// splitLines 按行拆分文本，空文本没有任何行
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
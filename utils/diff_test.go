@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []DiffLine
+	}{
+		{
+			name: "both empty",
+			from: "",
+			to:   "",
+			want: []DiffLine{},
+		},
+		{
+			name: "from empty",
+			from: "",
+			to:   "a\nb",
+			want: []DiffLine{{"insert", "a"}, {"insert", "b"}},
+		},
+		{
+			name: "to empty",
+			from: "a\nb",
+			to:   "",
+			want: []DiffLine{{"delete", "a"}, {"delete", "b"}},
+		},
+		{
+			name: "unchanged",
+			from: "a\nb",
+			to:   "a\nb",
+			want: []DiffLine{{"equal", "a"}, {"equal", "b"}},
+		},
+		{
+			name: "crlf normalized",
+			from: "a\r\nb",
+			to:   "a\nb",
+			want: []DiffLine{{"equal", "a"}, {"equal", "b"}},
+		},
+		{
+			name: "line replaced",
+			from: "a\nb\nc",
+			to:   "a\nx\nc",
+			want: []DiffLine{{"equal", "a"}, {"delete", "b"}, {"insert", "x"}, {"equal", "c"}},
+		},
+		{
+			name: "line inserted in the middle",
+			from: "a\nc",
+			to:   "a\nb\nc",
+			want: []DiffLine{{"equal", "a"}, {"insert", "b"}, {"equal", "c"}},
+		},
+		{
+			name: "line moved",
+			from: "a\nb\nc\nd",
+			to:   "b\nc\nd\na",
+			want: []DiffLine{{"delete", "a"}, {"equal", "b"}, {"equal", "c"}, {"equal", "d"}, {"insert", "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesMinimal 差异应能还原两段文本，且公共行数等于最长公共子序列长度
+func TestDiffLinesMinimal(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		lcs  int
+	}{
+		{"a\nb\nc\nb\nd\na\nb", "b\nd\nc\na\nb\na", 4},
+		{"x\ny\nz", "z\ny\nx", 1},
+		{"1\n2\n3\n4\n5\n6", "6\n1\n3\n5\n2\n4", 3},
+		{"same\nsame\nsame", "same\nsame", 2},
+	}
+
+	for _, tt := range tests {
+		lines := DiffLines(tt.from, tt.to)
+		var from, to []string
+		equal := 0
+		for _, line := range lines {
+			switch line.Op {
+			case "equal":
+				from = append(from, line.Text)
+				to = append(to, line.Text)
+				equal++
+			case "delete":
+				from = append(from, line.Text)
+			case "insert":
+				to = append(to, line.Text)
+			}
+		}
+		if strings.Join(from, "\n") != tt.from || strings.Join(to, "\n") != tt.to {
+			t.Errorf("DiffLines(%q, %q) does not reproduce inputs: %v", tt.from, tt.to, lines)
+		}
+		if equal != tt.lcs {
+			t.Errorf("DiffLines(%q, %q) has %d equal lines, want %d", tt.from, tt.to, equal, tt.lcs)
+		}
+	}
+}
+
+// TestDiffLinesLimit 超过行数上限时整段替换
+func TestDiffLinesLimit(t *testing.T) {
+	from := make([]string, maxDiffLines+1)
+	to := make([]string, maxDiffLines+1)
+	for i := range from {
+		from[i] = "old"
+		to[i] = "new"
+	}
+	lines := DiffLines("head\n"+strings.Join(from, "\n"), "head\n"+strings.Join(to, "\n"))
+	if len(lines) != 1+2*(maxDiffLines+1) {
+		t.Fatalf("len(lines) = %d, want %d", len(lines), 1+2*(maxDiffLines+1))
+	}
+	if lines[0].Op != "equal" || lines[1].Op != "delete" || lines[len(lines)-1].Op != "insert" {
+		t.Errorf("unexpected ops: %v %v %v", lines[0], lines[1], lines[len(lines)-1])
+	}
+}